@@ -4,8 +4,11 @@ go 1.13
 
 require (
 	github.com/apache/dubbo-go-hessian2 v1.12.4
-	github.com/cloudwego/kitex v0.8.0
-	github.com/cloudwego/thriftgo v0.3.3
+	github.com/cloudwego/kitex v0.9.0
+	github.com/cloudwego/netpoll v0.6.0
+	github.com/cloudwego/thriftgo v0.3.6
+	github.com/dubbogo/gost v1.13.1
 	github.com/stretchr/testify v1.8.2
 	golang.org/x/net v0.17.0 // indirect
+	google.golang.org/protobuf v1.28.1
 )
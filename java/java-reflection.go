@@ -29,26 +29,51 @@ import (
 // IDL Path: java.thrift
 
 var file_java_thrift_go_types = []interface{}{
-	(*Object)(nil),    // Struct 0: java.Object
-	(*Date)(nil),      // Struct 1: java.Date
-	(*Exception)(nil), // Struct 2: java.Exception
+	(*Object)(nil),        // Struct 0: java.Object
+	(*Date)(nil),          // Struct 1: java.Date
+	(*Exception)(nil),     // Struct 2: java.Exception
+	(*Boolean)(nil),       // Struct 3: java.Boolean
+	(*Byte)(nil),          // Struct 4: java.Byte
+	(*Short)(nil),         // Struct 5: java.Short
+	(*Integer)(nil),       // Struct 6: java.Integer
+	(*Long)(nil),          // Struct 7: java.Long
+	(*Double)(nil),        // Struct 8: java.Double
+	(*BigDecimal)(nil),    // Struct 9: java.BigDecimal
+	(*BigInteger)(nil),    // Struct 10: java.BigInteger
+	(*LocalDate)(nil),     // Struct 11: java.LocalDate
+	(*LocalTime)(nil),     // Struct 12: java.LocalTime
+	(*LocalDateTime)(nil), // Struct 13: java.LocalDateTime
+	(*Instant)(nil),       // Struct 14: java.Instant
+	(*Duration)(nil),      // Struct 15: java.Duration
+	(*ZonedDateTime)(nil), // Struct 16: java.ZonedDateTime
 }
 
 var (
 	file_java_thrift      *thrift_reflection.FileDescriptor
 	file_idl_java_rawDesc = []byte{
-		0x1f, 0x8b, 0x8, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xff, 0x8c, 0x90, 0x41, 0xae, 0x82, 0x30,
-		0x18, 0x84, 0xe7, 0x41, 0xe1, 0x89, 0x3f, 0x58, 0x13, 0xef, 0xc1, 0x25, 0xd4, 0x8d, 0xb, 0x3d,
-		0xc3, 0x2f, 0xa9, 0x8, 0xa9, 0x60, 0xa4, 0x12, 0x8f, 0x6f, 0x5a, 0x8a, 0x2e, 0x8c, 0x91, 0xd5,
-		0xd7, 0xa4, 0x33, 0xdf, 0xa4, 0x25, 0xfc, 0x1, 0xa0, 0x9a, 0x7b, 0xce, 0xcd, 0xf9, 0x56, 0x9d,
-		0x4c, 0x86, 0x80, 0x8, 0x0, 0x32, 0x84, 0xee, 0x60, 0x3, 0x41, 0xd9, 0x2, 0x10, 0x36, 0x26,
-		0x21, 0x52, 0x7b, 0x2d, 0x11, 0x59, 0x86, 0x9f, 0x6, 0x42, 0x0, 0x20, 0x3e, 0x1c, 0x6b, 0x55,
-		0x18, 0x89, 0x30, 0x1d, 0x6c, 0x82, 0xa4, 0xb7, 0x65, 0x3b, 0xee, 0x79, 0xad, 0xb9, 0xeb, 0xf6,
-		0x7c, 0x51, 0xe3, 0xc6, 0xd2, 0x29, 0x34, 0x37, 0x65, 0x3e, 0x54, 0x9, 0x91, 0x6d, 0xe2, 0xdb,
-		0x82, 0xd8, 0xb0, 0x51, 0xd3, 0xfd, 0xb, 0x27, 0xb8, 0x9b, 0x4a, 0xe7, 0xb6, 0xf8, 0xcb, 0x9e,
-		0x6c, 0x1f, 0x85, 0xba, 0x9a, 0xaa, 0x6d, 0xa6, 0x4f, 0xac, 0xde, 0x4f, 0x78, 0xb5, 0xc7, 0x1d,
-		0x89, 0xd8, 0xff, 0xdb, 0xbf, 0xe7, 0xcc, 0x33, 0xf1, 0x9c, 0x3b, 0xe2, 0x19, 0x0, 0x0, 0xff,
-		0xff, 0xf9, 0xde, 0xba, 0xfa, 0x93, 0x1, 0x0, 0x0,
+		0x1f, 0x8b, 0x8, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xff, 0xa4, 0x95, 0xdf, 0x4e, 0xc2, 0x30,
+		0x14, 0xc6, 0x8f, 0x30, 0xfe, 0x96, 0x39, 0x51, 0x13, 0xdf, 0x62, 0xf, 0x81, 0xf3, 0x2, 0x43,
+		0xf4, 0x42, 0xaf, 0xbc, 0x3b, 0xcc, 0x3a, 0x4a, 0xba, 0xd6, 0x8c, 0x42, 0xe4, 0xed, 0x4d, 0xb7,
+		0xb2, 0x4a, 0xd4, 0xb4, 0x85, 0xab, 0x43, 0xc2, 0x7e, 0xdf, 0x8f, 0x7e, 0xcd, 0x61, 0x4, 0x2e,
+		0x0, 0x80, 0xac, 0x71, 0x87, 0xa9, 0x5a, 0x55, 0xec, 0x43, 0xc5, 0xd0, 0x21, 0x4, 0x0, 0x20,
+		0x86, 0x6e, 0xfd, 0x41, 0x3f, 0xd0, 0x29, 0x24, 0x0, 0x44, 0xfa, 0xb1, 0x4, 0xa2, 0x89, 0xfe,
+		0x3a, 0x81, 0x9e, 0x9e, 0xd3, 0xdf, 0x9, 0x4, 0x3a, 0x0, 0xd0, 0x7f, 0x5e, 0xae, 0x69, 0xae,
+		0x12, 0xe8, 0x4e, 0x9a, 0xb4, 0x88, 0x24, 0x26, 0x2d, 0x7e, 0xc4, 0x1d, 0xde, 0x73, 0xdc, 0x6c,
+		0x9e, 0xb0, 0xa4, 0x7, 0xc7, 0x55, 0x1d, 0xc1, 0x51, 0x14, 0x69, 0x83, 0x12, 0xe8, 0x69, 0x12,
+		0xfe, 0x33, 0x44, 0x19, 0x2a, 0xea, 0x9f, 0x7f, 0x59, 0x7, 0x6c, 0x15, 0xe3, 0xa9, 0x6, 0x5d,
+		0xe9, 0xa3, 0x87, 0xaf, 0x9c, 0x7e, 0x2a, 0x26, 0x85, 0xbf, 0xe2, 0xc6, 0x1e, 0xa1, 0xa5, 0x5d,
+		0x9e, 0xc1, 0x4c, 0x4a, 0x4e, 0x31, 0xc0, 0x32, 0xb5, 0x16, 0xc3, 0x3a, 0x9b, 0x9a, 0xed, 0xc3,
+		0x9b, 0x6a, 0x4, 0x7b, 0x77, 0x53, 0xbd, 0x97, 0x95, 0xac, 0x2, 0x2e, 0x3a, 0xb1, 0xf1, 0x35,
+		0xe9, 0x6c, 0x68, 0x2e, 0x14, 0x2d, 0x68, 0x75, 0x52, 0x43, 0x86, 0x75, 0x36, 0xb4, 0x90, 0xa2,
+		0x38, 0xa9, 0x21, 0xd, 0xba, 0xd2, 0xfb, 0x99, 0xdc, 0x2e, 0x79, 0xc0, 0xd, 0xfc, 0xd8, 0x85,
+		0x6, 0x75, 0x19, 0xc6, 0x33, 0x56, 0x64, 0x34, 0x67, 0x25, 0x72, 0x7f, 0xcb, 0x6d, 0x1d, 0x53,
+		0xa2, 0x5a, 0xa5, 0x16, 0xf7, 0x31, 0x5, 0x5f, 0xc8, 0xb1, 0xc9, 0xf3, 0x4e, 0x46, 0xb, 0x99,
+		0x23, 0xf, 0x5b, 0xf2, 0x66, 0x3, 0x15, 0x2b, 0x69, 0xda, 0xd2, 0x7e, 0x9e, 0x57, 0x56, 0x9e,
+		0xe1, 0xd1, 0xb4, 0xcb, 0x13, 0xb7, 0xbf, 0x28, 0xcc, 0x75, 0xf7, 0xc7, 0x99, 0x7c, 0x7c, 0x83,
+		0xb9, 0xd8, 0x28, 0x14, 0x1, 0x9b, 0x39, 0xb5, 0x26, 0xc3, 0xba, 0x1c, 0xc3, 0x6c, 0x5b, 0x61,
+		0xd8, 0x9f, 0xe4, 0xb5, 0x95, 0x1c, 0x60, 0x67, 0x73, 0x6f, 0x52, 0xd0, 0xf7, 0xb3, 0x9a, 0x3b,
+		0x4a, 0x38, 0xf8, 0x12, 0xe8, 0x9b, 0x77, 0xd9, 0xc0, 0xcc, 0xa1, 0x99, 0x23, 0x33, 0xc7, 0xf5,
+		0x84, 0xef, 0x0, 0x0, 0x0, 0xff, 0xff, 0xfe, 0xd6, 0x22, 0x39, 0x27, 0x7, 0x0, 0x0,
 	}
 )
 
@@ -20,8 +20,11 @@
 package java
 
 import (
+	"math/big"
 	"time"
 
+	"github.com/apache/dubbo-go-hessian2/java8_time"
+	gost_big "github.com/dubbogo/gost/math/big"
 	hessian2_exception "github.com/kitex-contrib/codec-dubbo/pkg/hessian2/exception"
 )
 
@@ -42,3 +45,109 @@ type Exception = hessian2_exception.Exception
 func NewException(detailMessage string) *Exception {
 	return hessian2_exception.NewException(detailMessage)
 }
+
+// The nullable wrapper types of java primitives. Fields of these types are pointers in kitex generated codes,
+// a nil pointer is encoded as java null and vice versa.
+
+type Boolean = bool
+
+func NewBoolean() *Boolean {
+	return new(Boolean)
+}
+
+type Byte = int8
+
+func NewByte() *Byte {
+	return new(Byte)
+}
+
+type Short = int16
+
+func NewShort() *Short {
+	return new(Short)
+}
+
+type Integer = int32
+
+func NewInteger() *Integer {
+	return new(Integer)
+}
+
+type Long = int64
+
+func NewLong() *Long {
+	return new(Long)
+}
+
+type Double = float64
+
+func NewDouble() *Double {
+	return new(Double)
+}
+
+// BigDecimal is an exact decimal, use FromString and String to convert it from and to strings.
+type BigDecimal = gost_big.Decimal
+
+func NewBigDecimal() *BigDecimal {
+	return new(BigDecimal)
+}
+
+// BigInteger wraps big.Int, use SetValue and Value to access the big.Int.
+type BigInteger = gost_big.Integer
+
+func NewBigInteger() *BigInteger {
+	return new(BigInteger)
+}
+
+// NewBigIntegerFromUint64 converts v to BigInteger, so that uint64 could be passed to java losslessly.
+// uint64 is encoded as long by default, which overflows if v is larger than math.MaxInt64.
+func NewBigIntegerFromUint64(v uint64) *BigInteger {
+	i := new(BigInteger)
+	i.SetValue(new(big.Int).SetUint64(v))
+	return i
+}
+
+// The java.time types. Java serializes them through the replacement handles of hessian-lite,
+// e.g. com.alibaba.com.caucho.hessian.io.java8.LocalDateHandle, and these types are encoded and decoded the same way.
+
+// LocalDate is a date without time-zone, e.g. 2020-06-16.
+type LocalDate = java8_time.LocalDate
+
+func NewLocalDate() *LocalDate {
+	return new(LocalDate)
+}
+
+// LocalTime is a time without time-zone, Nano is the nano-of-second.
+type LocalTime = java8_time.LocalTime
+
+func NewLocalTime() *LocalTime {
+	return new(LocalTime)
+}
+
+// LocalDateTime is a date-time without time-zone.
+type LocalDateTime = java8_time.LocalDateTime
+
+func NewLocalDateTime() *LocalDateTime {
+	return new(LocalDateTime)
+}
+
+// Instant is a point on the time-line, Seconds are counted from the epoch of 1970-01-01T00:00:00Z.
+type Instant = java8_time.Instant
+
+func NewInstant() *Instant {
+	return new(Instant)
+}
+
+// Duration is a time-based amount of time, e.g. 34.5 seconds.
+type Duration = java8_time.Duration
+
+func NewDuration() *Duration {
+	return new(Duration)
+}
+
+// ZonedDateTime is a date-time with an offset and a time-zone, e.g. 2020-06-16T06:05:04+08:00[Asia/Shanghai].
+type ZonedDateTime = java8_time.ZonedDateTime
+
+func NewZonedDateTime() *ZonedDateTime {
+	return new(ZonedDateTime)
+}
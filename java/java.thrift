@@ -5,3 +5,31 @@ struct Object {} (JavaClassName="java.lang.Object")
 struct Date {} (JavaClassName="java.util.Date")
 
 struct Exception {} (JavaClassName="java.lang.Exception")
+
+struct Boolean {} (JavaClassName="java.lang.Boolean")
+
+struct Byte {} (JavaClassName="java.lang.Byte")
+
+struct Short {} (JavaClassName="java.lang.Short")
+
+struct Integer {} (JavaClassName="java.lang.Integer")
+
+struct Long {} (JavaClassName="java.lang.Long")
+
+struct Double {} (JavaClassName="java.lang.Double")
+
+struct BigDecimal {} (JavaClassName="java.math.BigDecimal")
+
+struct BigInteger {} (JavaClassName="java.math.BigInteger")
+
+struct LocalDate {} (JavaClassName="java.time.LocalDate")
+
+struct LocalTime {} (JavaClassName="java.time.LocalTime")
+
+struct LocalDateTime {} (JavaClassName="java.time.LocalDateTime")
+
+struct Instant {} (JavaClassName="java.time.Instant")
+
+struct Duration {} (JavaClassName="java.time.Duration")
+
+struct ZonedDateTime {} (JavaClassName="java.time.ZonedDateTime")
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+const (
+	// requestAttachmentsKey is the key of Invocation extra storing the request attachments on the server side.
+	requestAttachmentsKey = "dubbo_request_attachments"
+	// responseAttachmentsKey is the key of Invocation extra storing the response attachments on the server side.
+	responseAttachmentsKey = "dubbo_response_attachments"
+)
+
+type attachmentsCtxKey struct{}
+
+// attachmentsHolder is stored in the context of client calls.
+type attachmentsHolder struct {
+	// send is sent with the request
+	send map[string]interface{}
+	// recv is received with the response
+	recv map[string]interface{}
+}
+
+// WithAttachment returns a copy of ctx carrying the attachment which is sent with the request on the client side.
+// Different from TransInfo, value could be any type supported by hessian2, e.g. int64, bool and maps.
+// Attachments of the response are recorded in the returned context and could be read by GetAttachments
+// after the call returns.
+func WithAttachment(ctx context.Context, key string, value interface{}) context.Context {
+	return WithAttachments(ctx, map[string]interface{}{key: value})
+}
+
+// WithAttachments is the batch version of WithAttachment. WithAttachments(ctx, nil) could be used to
+// receive the attachments of the response without sending any.
+func WithAttachments(ctx context.Context, attachments map[string]interface{}) context.Context {
+	holder := &attachmentsHolder{send: make(map[string]interface{})}
+	if old, ok := ctx.Value(attachmentsCtxKey{}).(*attachmentsHolder); ok {
+		for k, v := range old.send {
+			holder.send[k] = v
+		}
+	}
+	for k, v := range attachments {
+		holder.send[k] = v
+	}
+	return context.WithValue(ctx, attachmentsCtxKey{}, holder)
+}
+
+// GetAttachments returns the attachments received.
+// On the server side, ctx should be the context of the handler and the attachments of the request are returned.
+// On the client side, ctx should be created by WithAttachment or WithAttachments and the attachments of the response
+// are returned after the call returns.
+func GetAttachments(ctx context.Context) map[string]interface{} {
+	if holder, ok := ctx.Value(attachmentsCtxKey{}).(*attachmentsHolder); ok {
+		return holder.recv
+	}
+	if ri := rpcinfo.GetRPCInfo(ctx); ri != nil {
+		attachments, _ := ri.Invocation().Extra(requestAttachmentsKey).(map[string]interface{})
+		return attachments
+	}
+	return nil
+}
+
+// SetResponseAttachment sets the attachment sent with the response on the server side,
+// ctx should be the context of the handler.
+func SetResponseAttachment(ctx context.Context, key string, value interface{}) {
+	ri := rpcinfo.GetRPCInfo(ctx)
+	if ri == nil {
+		return
+	}
+	setter, ok := ri.Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return
+	}
+	attachments, _ := ri.Invocation().Extra(responseAttachmentsKey).(map[string]interface{})
+	if attachments == nil {
+		attachments = make(map[string]interface{})
+		setter.SetExtra(responseAttachmentsKey, attachments)
+	}
+	attachments[key] = value
+}
+
+// getSendAttachments returns the attachments carried by ctx (client side) or set by SetResponseAttachment (server side).
+func getSendAttachments(ctx context.Context, message remote.Message) map[string]interface{} {
+	if message.RPCRole() == remote.Client {
+		if holder, ok := ctx.Value(attachmentsCtxKey{}).(*attachmentsHolder); ok {
+			return holder.send
+		}
+		return nil
+	}
+	attachments, _ := message.RPCInfo().Invocation().Extra(responseAttachmentsKey).(map[string]interface{})
+	return attachments
+}
+
+// setRecvAttachments records the received attachments so that they could be read by GetAttachments.
+func setRecvAttachments(ctx context.Context, message remote.Message, attachments map[string]interface{}) {
+	if message.RPCRole() == remote.Client {
+		if holder, ok := ctx.Value(attachmentsCtxKey{}).(*attachmentsHolder); ok {
+			holder.recv = attachments
+		}
+		return
+	}
+	if setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter); ok {
+		setter.SetExtra(requestAttachmentsKey, attachments)
+	}
+}
+
+// getResponseAttachments merges the Tags of the response message and the attachments set by SetResponseAttachment.
+func getResponseAttachments(ctx context.Context, message remote.Message) map[string]interface{} {
+	sendAttachments := getSendAttachments(ctx, message)
+	if len(sendAttachments) == 0 {
+		return message.Tags()
+	}
+	attachments := make(map[string]interface{}, len(message.Tags())+len(sendAttachments))
+	for k, v := range message.Tags() {
+		attachments[k] = v
+	}
+	for k, v := range sendAttachments {
+		attachments[k] = v
+	}
+	return attachments
+}
+
+// setRPCTimeout applies the timeout attachment of the request in milliseconds as the RPCTimeout on the server side.
+// The handler context carries the deadline if server.WithEnableContextTimeout is enabled.
+func setRPCTimeout(message remote.Message) {
+	var timeout int64
+	switch v := message.Tags()[dubbo_spec.TIMEOUT_KEY].(type) {
+	case string:
+		timeout, _ = strconv.ParseInt(v, 10, 64)
+	case int32:
+		timeout = int64(v)
+	case int64:
+		timeout = v
+	}
+	if timeout <= 0 {
+		return
+	}
+	if cfg := rpcinfo.AsMutableRPCConfig(message.RPCInfo().Config()); cfg != nil {
+		_ = cfg.SetRPCTimeout(time.Duration(timeout) * time.Millisecond)
+	}
+}
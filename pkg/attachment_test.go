@@ -0,0 +1,215 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJavaClassName = "org.cloudwego.kitex.samples.api.GreetProvider"
+
+func TestWithAttachment(t *testing.T) {
+	ctx := WithAttachment(context.Background(), "k1", int64(1))
+	newCtx := WithAttachments(ctx, map[string]interface{}{"k2": true})
+
+	msg := remote.NewMessage(nil, nil, nil, remote.Call, remote.Client)
+	assert.Equal(t, map[string]interface{}{"k1": int64(1)}, getSendAttachments(ctx, msg))
+	assert.Equal(t, map[string]interface{}{"k1": int64(1), "k2": true}, getSendAttachments(newCtx, msg))
+	assert.Nil(t, getSendAttachments(context.Background(), msg))
+}
+
+func TestClientAttachments(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+	ctx := WithAttachment(context.Background(), "k1", int64(1))
+	// reserved keys could not be overwritten
+	ctx = WithAttachment(ctx, dubbo_spec.PATH_KEY, "path")
+
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", GenericInvokeMethod, nil, nil),
+		rpcinfo.NewInvocation(GenericServiceName, GenericInvokeMethod), rpcinfo.NewRPCConfig(), nil)
+	sendMsg := remote.NewMessage(&GenericInvokeArgs{Method: "Greet"}, genericServiceInfo, ri, remote.Call, remote.Client)
+	out := remote.NewReaderWriterBuffer(1024)
+	assert.Nil(t, codec.Encode(ctx, sendMsg, out))
+	buf, err := out.Bytes()
+	assert.Nil(t, err)
+
+	// skip service, types and args
+	decoder := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:])
+	for i := 0; i < 8; i++ {
+		_, err := decoder.Decode()
+		assert.Nil(t, err)
+	}
+	attachments, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), attachments.(map[interface{}]interface{})["k1"])
+	assert.Equal(t, testJavaClassName, attachments.(map[interface{}]interface{})[dubbo_spec.PATH_KEY])
+
+	// response with typed attachments
+	encoder := hessian2.NewEncoder()
+	assert.Nil(t, encoder.Encode(dubbo_spec.RESPONSE_NULL_VALUE_WITH_ATTACHMENTS))
+	assert.Nil(t, encoder.Encode(map[interface{}]interface{}{"k2": true, "k3": map[interface{}]interface{}{"key": "val"}}))
+	body := encoder.Buffer()
+	header := &dubbo_spec.DubboHeader{
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		Status:          dubbo_spec.StatusOK,
+		RequestID:       uint64(ri.Invocation().SeqID()),
+		DataLength:      uint32(len(body)),
+	}
+	recvMsg := remote.NewMessage(new(GenericInvokeResult), genericServiceInfo, ri, remote.Reply, remote.Client)
+	assert.Nil(t, codec.Decode(ctx, recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))))
+	assert.Equal(t, map[string]interface{}{
+		"k2": true,
+		"k3": map[interface{}]interface{}{"key": "val"},
+	}, GetAttachments(ctx))
+}
+
+func TestServerAttachments(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+
+	encoder := hessian2.NewEncoder()
+	for _, v := range []interface{}{
+		dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, "", EchoMethod, echoTypes, "OK",
+		map[interface{}]interface{}{dubbo_spec.PATH_KEY: testJavaClassName, "k1": int64(1), "k2": true},
+	} {
+		assert.Nil(t, encoder.Encode(v))
+	}
+	body := encoder.Buffer()
+	header := &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		DataLength:      uint32(len(body)),
+	}
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+		rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+	svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+	recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+	assert.Nil(t, codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))))
+
+	ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), ri)
+	attachments := GetAttachments(ctx)
+	assert.Equal(t, int64(1), attachments["k1"])
+	assert.Equal(t, true, attachments["k2"])
+
+	SetResponseAttachment(ctx, "k3", int32(3))
+	sendMsg := remote.NewMessage(&GenericInvokeResult{Success: "OK"}, svcInfo, ri, remote.Reply, remote.Server)
+	out := remote.NewReaderWriterBuffer(1024)
+	assert.Nil(t, codec.Encode(ctx, sendMsg, out))
+	buf, err := out.Bytes()
+	assert.Nil(t, err)
+
+	decoder := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:])
+	payloadType, err := dubbo_spec.DecodePayloadType(decoder)
+	assert.Nil(t, err)
+	assert.Equal(t, dubbo_spec.RESPONSE_VALUE_WITH_ATTACHMENTS, payloadType)
+	_, err = decoder.Decode()
+	assert.Nil(t, err)
+	respAttachments, err := decoder.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), respAttachments.(map[interface{}]interface{})["k3"])
+}
+
+// attachmentTestArgs is the same as the args generated by kitex for Greet(req string, size i32).
+type attachmentTestArgs struct {
+	Req  string
+	Size int32
+}
+
+func (p *attachmentTestArgs) Encode(e iface.Encoder) error {
+	if err := e.Encode(p.Req); err != nil {
+		return err
+	}
+	return e.Encode(p.Size)
+}
+
+func (p *attachmentTestArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Req); err != nil {
+		return err
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Size)
+}
+
+func TestServerRPCTimeout(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+	svcInfo := &serviceinfo.ServiceInfo{
+		ServiceName: "GreetService",
+		Methods: map[string]serviceinfo.MethodInfo{
+			"Greet": serviceinfo.NewMethodInfo(nil,
+				func() interface{} { return new(attachmentTestArgs) },
+				func() interface{} { return new(attachmentTestArgs) },
+				false,
+			),
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		timeout  interface{}
+		expected time.Duration
+	}{
+		{desc: "string", timeout: "1000", expected: time.Second},
+		{desc: "int32", timeout: int32(500), expected: 500 * time.Millisecond},
+		{desc: "int64", timeout: int64(200), expected: 200 * time.Millisecond},
+		{desc: "invalid", timeout: "1s", expected: 0},
+		{desc: "absent", expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			attachments := map[interface{}]interface{}{dubbo_spec.PATH_KEY: testJavaClassName}
+			if test.timeout != nil {
+				attachments[dubbo_spec.TIMEOUT_KEY] = test.timeout
+			}
+			encoder := hessian2.NewEncoder()
+			for _, v := range []interface{}{
+				dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, "", "Greet", "Ljava/lang/String;I",
+				"world", int32(1), attachments,
+			} {
+				assert.Nil(t, encoder.Encode(v))
+			}
+			body := encoder.Buffer()
+			header := &dubbo_spec.DubboHeader{
+				IsRequest:       true,
+				SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+				DataLength:      uint32(len(body)),
+			}
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+			assert.Nil(t, codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))))
+			assert.Equal(t, &attachmentTestArgs{Req: "world", Size: 1}, recvMsg.Data())
+			assert.Equal(t, test.expected, ri.Config().RPCTimeout())
+		})
+	}
+}
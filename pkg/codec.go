@@ -32,6 +32,7 @@ import (
 	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
 	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
 	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/kitex-contrib/codec-dubbo/pkg/protobuf"
 )
 
 var _ remote.Codec = (*DubboCodec)(nil)
@@ -40,12 +41,18 @@ var _ remote.Codec = (*DubboCodec)(nil)
 type DubboCodec struct {
 	opt         *Options
 	methodCache hessian2.MethodCache
+	// stats records the invocation statistics for the telnet command count.
+	stats invocationStats
+	// readonly records the providers that have sent READONLY_EVENT on the client side.
+	readonly *readonlyProviders
+	// registrations records the services registered by the registries wrapped by TelnetRegistry.
+	registrations *registrations
 }
 
 // NewDubboCodec creates a new codec instance.
 func NewDubboCodec(opts ...Option) *DubboCodec {
 	o := newOptions(opts)
-	return &DubboCodec{opt: o}
+	return &DubboCodec{opt: o, readonly: newReadonlyProviders(), registrations: new(registrations)}
 }
 
 // Name codec name
@@ -53,39 +60,37 @@ func (m *DubboCodec) Name() string {
 	return "dubbo"
 }
 
-// Marshal encode method
+// Encode writes the dubbo package of message into out.
 func (m *DubboCodec) Encode(ctx context.Context, message remote.Message, out remote.ByteBuffer) error {
-	var payload []byte
-	var err error
-	var status dubbo_spec.StatusCode
-	// indicate whether this pkg is event
-	var eventFlag bool
-	msgType := message.MessageType()
-	switch msgType {
-	case remote.Call, remote.Oneway:
-		payload, err = m.encodeRequestPayload(ctx, message)
-	case remote.Exception:
-		payload, err = m.encodeExceptionPayload(ctx, message)
-		// todo(DMwangnima): refer to exception processing logic of dubbo-java, use status to determine if this exception
-		// is in outside layer.(eg. non-exist InterfaceName)
-		// for now, use StatusOK by default, regardless of whether it is in outside layer.
-		status = dubbo_spec.StatusOK
-	case remote.Reply:
-		payload, err = m.encodeResponsePayload(ctx, message)
-		status = dubbo_spec.StatusOK
-	case remote.Heartbeat:
-		payload, err = m.encodeHeartbeatPayload(ctx, message)
-		status = dubbo_spec.StatusOK
-		eventFlag = true
-	default:
-		return fmt.Errorf("unsupported MessageType: %v", msgType)
+	if message.RPCRole() == remote.Server {
+		if msgType := message.MessageType(); msgType == remote.Reply || msgType == remote.Exception {
+			m.endInvocation(message)
+		}
+		if cmd, ok := getTelnetCommand(message); ok {
+			return m.encodeTelnetReply(message, cmd, out)
+		}
 	}
-
+	serialization := m.getSerialization(message)
+	encoder := serialization.NewEncoder()
+	status, eventFlag, err := m.encodePayload(ctx, message, encoder)
 	if err != nil {
 		return err
 	}
+	payload := encoder.Buffer()
+	if err := checkPayload(len(payload), m.opt.MaxPayload); err != nil {
+		if message.RPCRole() == remote.Client {
+			return err
+		}
+		// the same as dubbo-java, provider replies the error message with StatusBadResponse instead
+		encoder = serialization.NewEncoder()
+		if err := m.encodeErrorMessagePayload(ctx, err, encoder); err != nil {
+			return err
+		}
+		status, eventFlag = dubbo_spec.StatusBadResponse, false
+		payload = encoder.Buffer()
+	}
 
-	header := m.buildDubboHeader(message, status, len(payload), eventFlag)
+	header := m.buildDubboHeader(message, serialization.ID(), status, len(payload), eventFlag)
 
 	// write header
 	if err := header.Encode(out); err != nil {
@@ -99,12 +104,47 @@ func (m *DubboCodec) Encode(ctx context.Context, message remote.Message, out rem
 	return nil
 }
 
-func (m *DubboCodec) encodeRequestPayload(ctx context.Context, message remote.Message) (buf []byte, err error) {
-	encoder := hessian2.NewEncoder()
+// encodePayload encodes the body of message with encoder, and returns the status and the event flag of dubbo header.
+func (m *DubboCodec) encodePayload(ctx context.Context, message remote.Message, encoder iface.Encoder) (status dubbo_spec.StatusCode, eventFlag bool, err error) {
+	msgType := message.MessageType()
+	switch msgType {
+	case remote.Call, remote.Oneway:
+		err = m.encodeRequestPayload(ctx, message, encoder)
+	case remote.Exception:
+		// use status to determine if this exception is in outside layer.(eg. non-exist InterfaceName)
+		errRaw, _ := message.Data().(error)
+		status = getStatusCode(errRaw)
+		if status == dubbo_spec.StatusOK {
+			err = m.encodeExceptionPayload(ctx, message, encoder)
+		} else {
+			err = m.encodeErrorMessagePayload(ctx, errRaw, encoder)
+		}
+	case remote.Reply:
+		err = m.encodeResponsePayload(ctx, message, encoder)
+		status = dubbo_spec.StatusOK
+	case remote.Heartbeat:
+		status = dubbo_spec.StatusOK
+		// rejected requests and $echo are replied as normal responses
+		if rejection, ok := getRejection(message); ok {
+			status = getRejectionStatus(rejection)
+			err = m.encodeErrorMessagePayload(ctx, rejection, encoder)
+		} else if echo, ok := getEchoResult(message); ok {
+			err = m.encodeEchoPayload(ctx, echo, encoder)
+		} else {
+			err = m.encodeHeartbeatPayload(ctx, message, encoder)
+			eventFlag = true
+		}
+	default:
+		err = fmt.Errorf("unsupported MessageType: %v", msgType)
+	}
+	return
+}
+
+func (m *DubboCodec) encodeRequestPayload(ctx context.Context, message remote.Message, encoder iface.Encoder) error {
 
 	service := &dubbo_spec.Service{
 		ProtocolVersion: dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION,
-		Path:            m.opt.JavaClassName,
+		Path:            m.getJavaClassName(message),
 		Version:         message.RPCInfo().To().DefaultTag(registries.DubboServiceVersionKey, ""),
 		Method:          message.RPCInfo().Invocation().MethodName(),
 		Timeout:         message.RPCInfo().Config().RPCTimeout(),
@@ -118,91 +158,105 @@ func (m *DubboCodec) encodeRequestPayload(ctx context.Context, message remote.Me
 		service.Method = methodName
 	}
 
-	if err = m.messageServiceInfo(ctx, service, encoder); err != nil {
-		return nil, err
+	if err := m.messageServiceInfo(ctx, service, encoder); err != nil {
+		return err
 	}
 
-	if err = m.messageData(message, methodAnno, encoder); err != nil {
-		return nil, err
+	if err := m.messageData(message, methodAnno, encoder); err != nil {
+		return err
 	}
 
-	if err = m.messageAttachment(ctx, service, encoder); err != nil {
-		return nil, err
+	if err := m.messageAttachment(ctx, message, service, encoder); err != nil {
+		return err
 	}
 
-	return encoder.Buffer(), nil
+	return nil
 }
 
-func (m *DubboCodec) encodeResponsePayload(ctx context.Context, message remote.Message) (buf []byte, err error) {
-	encoder := hessian2.NewEncoder()
+func (m *DubboCodec) encodeResponsePayload(ctx context.Context, message remote.Message, encoder iface.Encoder) error {
 	var payloadType dubbo_spec.PayloadType
-	if len(message.Tags()) != 0 {
+	attachments := getResponseAttachments(ctx, message)
+	if len(attachments) != 0 {
 		payloadType = dubbo_spec.RESPONSE_VALUE_WITH_ATTACHMENTS
 	} else {
 		payloadType = dubbo_spec.RESPONSE_VALUE
 	}
 
 	if err := encoder.Encode(payloadType); err != nil {
-		return nil, err
+		return err
 	}
 
 	// encode data
-	data, ok := message.Data().(iface.Message)
+	data, ok := getMessage(message.Data())
 	if !ok {
-		return nil, fmt.Errorf("invalid data: not hessian2.MessageWriter")
+		return fmt.Errorf("invalid data: not hessian2.MessageWriter")
+	}
+	if isGenericResponse(message) {
+		data = generalizeResult(data)
 	}
 
 	if err := data.Encode(encoder); err != nil {
-		return nil, err
+		return err
 	}
 
 	// encode attachments if needed
 	if dubbo_spec.IsAttachmentsPayloadType(payloadType) {
-		if err := encoder.Encode(message.Tags()); err != nil {
-			return nil, err
+		if err := encoder.Encode(attachments); err != nil {
+			return err
 		}
 	}
 
-	return encoder.Buffer(), nil
+	return nil
 }
 
-func (m *DubboCodec) encodeExceptionPayload(ctx context.Context, message remote.Message) (buf []byte, err error) {
-	encoder := hessian2.NewEncoder()
+func (m *DubboCodec) encodeExceptionPayload(ctx context.Context, message remote.Message, encoder iface.Encoder) error {
 	var payloadType dubbo_spec.PayloadType
-	if len(message.Tags()) != 0 {
+	attachments := getResponseAttachments(ctx, message)
+	if len(attachments) != 0 {
 		payloadType = dubbo_spec.RESPONSE_WITH_EXCEPTION_WITH_ATTACHMENTS
 	} else {
 		payloadType = dubbo_spec.RESPONSE_WITH_EXCEPTION
 	}
 
 	if err := encoder.Encode(payloadType); err != nil {
-		return nil, err
+		return err
 	}
 
 	// encode exception
 	data := message.Data()
 	errRaw, ok := data.(error)
 	if !ok {
-		return nil, fmt.Errorf("%v exception does not implement Error", data)
+		return fmt.Errorf("%v exception does not implement Error", data)
 	}
 	// exception is wrapped by kerrors.DetailedError
 	if exception, ok := hessian2_exception.FromError(errRaw); ok {
 		if err := encoder.Encode(exception); err != nil {
-			return nil, err
+			return err
 		}
 	} else {
 		if err := encoder.Encode(hessian2_exception.NewException(errRaw.Error())); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	if dubbo_spec.IsAttachmentsPayloadType(payloadType) {
-		if err := encoder.Encode(message.Tags()); err != nil {
-			return nil, err
+		if err := encoder.Encode(attachments); err != nil {
+			return err
 		}
 	}
 
-	return encoder.Buffer(), nil
+	return nil
+}
+
+// encodeErrorMessagePayload encodes exception in the outer layer, dubbo-java reads the payload as an error message string
+// when status is not StatusOK.
+func (m *DubboCodec) encodeErrorMessagePayload(ctx context.Context, errRaw error, encoder iface.Encoder) error {
+
+	if err := encoder.Encode(errRaw.Error()); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // Event Flag set in dubbo header and 'N' body determines that this pkg is heartbeat.
@@ -211,23 +265,40 @@ func (m *DubboCodec) encodeExceptionPayload(ctx context.Context, message remote.
 // Arrays.equals(payload, getNullBytesOf(getSerializationById(proto)))
 // For hessian2, NullByte is 'N'.
 // As a result, we need to encode nil in heartbeat response body for both dubbo-go side and dubbo-java side.
-func (m *DubboCodec) encodeHeartbeatPayload(ctx context.Context, message remote.Message) (buf []byte, err error) {
-	encoder := hessian2.NewEncoder()
+func (m *DubboCodec) encodeHeartbeatPayload(ctx context.Context, message remote.Message, encoder iface.Encoder) error {
 
 	if err := encoder.Encode(nil); err != nil {
-		return nil, err
+		return err
+	}
+
+	return nil
+}
+
+// encodeEchoPayload encodes the argument of $echo as the response value.
+func (m *DubboCodec) encodeEchoPayload(ctx context.Context, echo *echoResult, encoder iface.Encoder) error {
+	if echo.value == nil {
+		if err := encoder.Encode(dubbo_spec.RESPONSE_NULL_VALUE); err != nil {
+			return err
+		}
+		return nil
 	}
 
-	return encoder.Buffer(), nil
+	if err := encoder.Encode(dubbo_spec.RESPONSE_VALUE); err != nil {
+		return err
+	}
+	if err := encoder.Encode(echo.value); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *DubboCodec) buildDubboHeader(message remote.Message, status dubbo_spec.StatusCode, size int, eventFlag bool) *dubbo_spec.DubboHeader {
+func (m *DubboCodec) buildDubboHeader(message remote.Message, serializationID uint8, status dubbo_spec.StatusCode, size int, eventFlag bool) *dubbo_spec.DubboHeader {
 	msgType := message.MessageType()
 	return &dubbo_spec.DubboHeader{
 		IsRequest:       msgType == remote.Call || msgType == remote.Oneway,
 		IsEvent:         eventFlag,
 		IsOneWay:        msgType == remote.Oneway,
-		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		SerializationID: serializationID,
 		Status:          status,
 		RequestID:       uint64(message.RPCInfo().Invocation().SeqID()),
 		DataLength:      uint32(size),
@@ -235,12 +306,20 @@ func (m *DubboCodec) buildDubboHeader(message remote.Message, status dubbo_spec.
 }
 
 func (m *DubboCodec) messageData(message remote.Message, methodAnno *hessian2.MethodAnnotation, e iface.Encoder) error {
-	data, ok := message.Data().(iface.Message)
+	data, ok := getMessage(message.Data())
 	if !ok {
 		return fmt.Errorf("invalid data: not hessian2.MessageWriter")
 	}
 
-	types, err := m.methodCache.GetTypes(data, methodAnno)
+	var types string
+	var err error
+	if _, ok := message.Data().(protobuf.Message); ok {
+		types, err = protobuf.GetTypes(message.Data())
+	} else if methodTypes, ok := m.getMethodTypes(message); ok {
+		types = methodTypes
+	} else {
+		types, err = m.methodCache.GetTypes(data, methodAnno)
+	}
 	if err != nil {
 		return err
 	}
@@ -266,7 +345,7 @@ func (m *DubboCodec) messageServiceInfo(ctx context.Context, service *dubbo_spec
 	return nil
 }
 
-func (m *DubboCodec) messageAttachment(ctx context.Context, service *dubbo_spec.Service, e iface.Encoder) error {
+func (m *DubboCodec) messageAttachment(ctx context.Context, message remote.Message, service *dubbo_spec.Service, e iface.Encoder) error {
 	attachment := dubbo_spec.NewAttachment(
 		service.Path,
 		service.Group,
@@ -275,10 +354,66 @@ func (m *DubboCodec) messageAttachment(ctx context.Context, service *dubbo_spec.
 		service.Timeout,
 		service.TransInfo,
 	)
+	// typed attachments specified by WithAttachment, the reserved keys could not be overwritten
+	for k, v := range getSendAttachments(ctx, message) {
+		if _, exists := attachment[k]; !exists {
+			attachment[k] = v
+		}
+	}
+	// ask the provider to convert map-form arguments to POJOs and return the result in generic form
+	if _, ok := message.Data().(*GenericInvokeArgs); ok {
+		attachment[dubbo_spec.GENERIC_KEY] = dubbo_spec.GENERIC_VALUE_TRUE
+	}
 	return e.Encode(attachment)
 }
 
+// getJavaClassName returns the InterfaceName of the service the message belongs to.
+func (m *DubboCodec) getJavaClassName(message remote.Message) string {
+	if svcInfo := message.ServiceInfo(); svcInfo != nil {
+		if name, ok := m.opt.JavaClassNames[svcInfo.ServiceName]; ok {
+			return name
+		}
+	}
+	return m.opt.JavaClassName
+}
+
+// getServiceName returns the kitex ServiceName serving the requested InterfaceName, group and version.
+func (m *DubboCodec) getServiceName(message remote.Message, service *dubbo_spec.Service) (string, error) {
+	path := service.Path
+	if m.opt.KeyedInterfaces[path] {
+		key := ServiceKey{InterfaceName: path, Group: service.Group, Version: service.Version}
+		if name, ok := m.opt.KeyedServiceNames[key.String()]; ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("dubbo requested service: %s, no kitex service serves the group and version", key)
+	}
+	if name, ok := m.opt.ServiceNames[path]; ok {
+		return name, nil
+	}
+	if name := m.opt.JavaClassName; path != name {
+		return "", fmt.Errorf("dubbo requested Path: %s, kitex service specified JavaClassName: %s", path, name)
+	}
+	// multi-service servers have no default kitex service, which should be configured by WithJavaClassNames instead.
+	svcInfo := message.ServiceInfo()
+	if svcInfo == nil {
+		return "", fmt.Errorf("dubbo requested Path: %s, no kitex service is configured for it by WithJavaClassNames", path)
+	}
+	return svcInfo.ServiceName, nil
+}
+
+// getMethodTypes returns the parameter types of the requested method derived from IDL by WithFileDescriptor.
+func (m *DubboCodec) getMethodTypes(message remote.Message) (string, bool) {
+	if len(m.opt.MethodTypes) == 0 || message.ServiceInfo() == nil {
+		return "", false
+	}
+	types, ok := m.opt.MethodTypes[message.ServiceInfo().ServiceName+"."+message.RPCInfo().To().Method()]
+	return types, ok
+}
+
 func (m *DubboCodec) getMethodAnnotation(message remote.Message) *hessian2.MethodAnnotation {
+	if _, ok := message.Data().(*GenericInvokeArgs); ok {
+		return genericInvokeAnnotation
+	}
 	methodKey := message.ServiceInfo().ServiceName + "." + message.RPCInfo().To().Method()
 	if m.opt.MethodAnnotations != nil {
 		if t, ok := m.opt.MethodAnnotations[methodKey]; ok {
@@ -290,11 +425,31 @@ func (m *DubboCodec) getMethodAnnotation(message remote.Message) *hessian2.Metho
 
 // Unmarshal decode method
 func (m *DubboCodec) Decode(ctx context.Context, message remote.Message, in remote.ByteBuffer) error {
+	if m.opt.Telnet && message.RPCRole() == remote.Server {
+		magic, err := in.Peek(1)
+		if err != nil {
+			return err
+		}
+		if isTelnetCommand(magic[0]) {
+			return m.decodeTelnetCommand(ctx, message, in)
+		}
+	}
 	// parse header part
-	header := new(dubbo_spec.DubboHeader)
-	if err := header.Decode(in); err != nil {
+	header, serialization, err := m.decodeHeader(message, in)
+	if err != nil || header == nil {
 		return err
 	}
+	// dubbo provider may send event requests proactively (eg. READONLY_EVENT) to client, and heartbeat responses
+	// may arrive after the heartbeat timed out.
+	// They are not responses of the request, so process them and continue to decode the next package.
+	for message.RPCRole() == remote.Client && header.IsEvent {
+		if err := m.processProviderEvent(ctx, header, serialization, message, in); err != nil {
+			return err
+		}
+		if header, serialization, err = m.decodeHeader(message, in); err != nil {
+			return err
+		}
+	}
 	if err := codec.SetOrCheckSeqID(int32(header.RequestID), message); err != nil {
 		return err
 	}
@@ -303,109 +458,212 @@ func (m *DubboCodec) Decode(ctx context.Context, message remote.Message, in remo
 	if header.IsRequest {
 		// heartbeat package
 		if header.IsEvent {
-			return m.decodeEventBody(ctx, header, message, in)
+			return m.decodeEventBody(ctx, header, serialization, message, in)
+		}
+		body, err := readBody(header, in)
+		if err != nil {
+			return err
 		}
-		return m.decodeRequestBody(ctx, header, message, in)
+		// the package has been read entirely, so that failures of the request are replied without closing the connection
+		if err := m.decodeRequestBody(ctx, serialization, message, body); err != nil {
+			return rejectInvocation(message, err, remote.ProtocolError)
+		}
+		if m.opt.Telnet && message.MessageType() != remote.Heartbeat {
+			m.beginInvocation(message)
+		}
+		return nil
 	}
 
 	if header.Status != dubbo_spec.StatusOK {
-		return m.decodeExceptionBody(ctx, header, message, in)
+		return m.decodeExceptionBody(ctx, header, serialization, message, in)
+	}
+	return m.decodeResponseBody(ctx, header, serialization, message, in)
+}
+
+// decodeHeader decodes the header of the next package and validates it before the body is read.
+// The header is nil if the request has been rejected by rejectInvocation, whose body is discarded.
+func (m *DubboCodec) decodeHeader(message remote.Message, in remote.ByteBuffer) (*dubbo_spec.DubboHeader, iface.Serialization, error) {
+	header := new(dubbo_spec.DubboHeader)
+	if err := header.Decode(in); err != nil {
+		return nil, nil, err
+	}
+	// the body is not decoded since it may be too large to be buffered, it is skipped for requests on the server side
+	if err := checkPayload(int(header.DataLength), m.opt.MaxPayload); err != nil {
+		if err := rejectRequest(header, message, in, err); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, nil
 	}
-	return m.decodeResponseBody(ctx, header, message, in)
+	serialization, err := m.decodeSerialization(header, message)
+	if err != nil {
+		if err := rejectSerialization(header, message, in, err); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, nil
+	}
+	return header, serialization, nil
 }
 
-func (m *DubboCodec) decodeEventBody(ctx context.Context, header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer) error {
+func (m *DubboCodec) decodeEventBody(ctx context.Context, header *dubbo_spec.DubboHeader, serialization iface.Serialization, message remote.Message, in remote.ByteBuffer) error {
 	body, err := readBody(header, in)
 	if err != nil {
 		return err
 	}
 
-	// entire body equals to BC_NULL determines that this request is a heartbeat
-	if len(body) == 1 && body[0] == hessian2.NULL {
+	// entire body equals to the null value of the serialization determines that this request is a heartbeat,
+	// e.g. BC_NULL of hessian2
+	if data, err := dubbo_spec.DecodeEvent(serialization.NewDecoder(body)); err == nil && data == nil {
 		message.SetMessageType(remote.Heartbeat)
 	}
-	// todo(DMwangnima): there are other events(READONLY_EVENT, WRITABLE_EVENT) in dubbo-java that we are planning to implement currently
+	// other events(READONLY_EVENT, WRITABLE_EVENT) are sent by provider, they are processed by processProviderEvent on the client side
 
 	return nil
 }
 
-func (m *DubboCodec) decodeRequestBody(ctx context.Context, header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer) error {
+// processProviderEvent processes event packages sent by dubbo provider on the client side.
+// Heartbeats are ignored since client could not reply them when decoding.
+func (m *DubboCodec) processProviderEvent(ctx context.Context, header *dubbo_spec.DubboHeader, serialization iface.Serialization, message remote.Message, in remote.ByteBuffer) error {
 	body, err := readBody(header, in)
 	if err != nil {
 		return err
 	}
 
-	decoder := hessian2.NewDecoder(body)
+	event, err := dubbo_spec.DecodeEvent(serialization.NewDecoder(body))
+	if err != nil {
+		return err
+	}
+	m.readonly.handleEvent(message.RPCInfo().To().Address(), event)
+	return nil
+}
+
+func (m *DubboCodec) decodeRequestBody(ctx context.Context, serialization iface.Serialization, message remote.Message, body []byte) (err error) {
+	defer func() {
+		// deserializers may panic on unexpected values, which are failures of the request as well
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decode request body failed: %v", r)
+		}
+	}()
+	var decoder iface.Decoder = serialization.NewDecoder(body)
 	service := new(dubbo_spec.Service)
 	if err := service.Decode(decoder); err != nil {
 		return err
 	}
+	var types string
+	if err := dubbo_spec.DecodeTo(decoder, &types); err != nil {
+		return err
+	}
 
-	if name := m.opt.JavaClassName; service.Path != name {
-		return fmt.Errorf("dubbo requested Path: %s, kitex service specified JavaClassName: %s", service.Path, name)
+	if m.opt.KeyedInterfaces[service.Path] {
+		if service.Group, decoder, err = peekGroup(decoder, types); err != nil {
+			return err
+		}
+	}
+	svcName, err := m.getServiceName(message, service)
+	if err != nil {
+		return remote.NewTransError(remote.UnknownService, err)
 	}
 
 	// decode payload
-	if types, err := decoder.Decode(); err != nil {
-		return err
-	} else if method, exists := m.opt.MethodNames[service.Method+types.(string)]; exists {
+	if isEcho(service.Method, types) {
+		return decodeEchoRequest(ctx, decoder, message)
+	}
+	// GenericService#$invoke, the real method and its types are specified by the arguments
+	var genericArgs *GenericInvokeArgs
+	if isGenericInvoke(service.Method, types) {
+		genericArgs = new(GenericInvokeArgs)
+		if err := genericArgs.Decode(decoder); err != nil {
+			return err
+		}
+		service.Method = genericArgs.Method
+		if types, err = hessian2.GetTypesByJavaTypes(genericArgs.ParameterTypes); err != nil {
+			return err
+		}
+	}
+	if method, exists := m.opt.MethodNames[svcName+"."+service.Method+types]; exists {
 		service.Method = method
+	} else if methods := m.opt.JavaMethods[svcName+"."+service.Method]; len(methods) == 1 {
+		// the parameter types are not exactly matched, e.g. a subclass is specified by the consumer,
+		// fall back to the method name if the java method is not overloaded.
+		service.Method = methods[0]
+	}
+	// unknown methods are rejected with UnknownMethod
+	if _, err := message.SpecifyServiceInfo(svcName, service.Method); err != nil {
+		return err
 	}
 	if err := codec.NewDataIfNeeded(service.Method, message); err != nil {
 		return err
 	}
-	arg, ok := message.Data().(iface.Message)
+	arg, ok := getMessage(message.Data())
 	if !ok {
 		return fmt.Errorf("invalid data: not hessian2.MessageReader")
 	}
-	if err := arg.Decode(decoder); err != nil {
+	if genericArgs != nil {
+		err = realizeGenericArgs(arg, genericArgs.Args)
+	} else {
+		err = arg.Decode(decoder)
+	}
+	if err != nil {
 		return err
 	}
 	if err := codec.SetOrCheckMethodName(service.Method, message); err != nil {
 		return err
 	}
 
-	if err := processAttachments(decoder, message); err != nil {
+	if err := processAttachments(ctx, decoder, message); err != nil {
 		return err
 	}
+	setRPCTimeout(message)
+
+	if genericArgs != nil && message.Tags()[dubbo_spec.GENERIC_KEY] == dubbo_spec.GENERIC_VALUE_TRUE {
+		if err := markGenericResponse(message); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 // decodeExceptionBody is responsible for processing exception in the outer layer which means business logic
 // in the remoting service has not been invoked. (eg. wrong request with non-exist InterfaceName)
-func (m *DubboCodec) decodeExceptionBody(ctx context.Context, header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer) error {
+func (m *DubboCodec) decodeExceptionBody(ctx context.Context, header *dubbo_spec.DubboHeader, serialization iface.Serialization, message remote.Message, in remote.ByteBuffer) error {
 	body, err := readBody(header, in)
 	if err != nil {
 		return err
 	}
 
-	decoder := hessian2.NewDecoder(body)
-	exception, err := decoder.Decode()
+	decoder := serialization.NewDecoder(body)
+	var exception interface{}
+	if _, ok := decoder.(iface.TypedDecoder); ok {
+		var str string
+		err = dubbo_spec.DecodeTo(decoder, &str)
+		exception = str
+	} else {
+		exception, err = decoder.Decode()
+	}
 	if err != nil {
 		return err
 	}
 	exceptionStr, ok := exception.(string)
 	if !ok {
-		return fmt.Errorf("exception %v is not of string", exception)
+		exceptionStr = fmt.Sprintf("%v", exception)
 	}
-	return fmt.Errorf("dubbo side exception: %s", exceptionStr)
+	return newStatusError(header.Status, exceptionStr)
 }
 
-func (m *DubboCodec) decodeResponseBody(ctx context.Context, header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer) error {
+func (m *DubboCodec) decodeResponseBody(ctx context.Context, header *dubbo_spec.DubboHeader, serialization iface.Serialization, message remote.Message, in remote.ByteBuffer) error {
 	body, err := readBody(header, in)
 	if err != nil {
 		return err
 	}
 
-	decoder := hessian2.NewDecoder(body)
+	decoder := serialization.NewDecoder(body)
 	payloadType, err := dubbo_spec.DecodePayloadType(decoder)
 	if err != nil {
 		return err
 	}
 	switch payloadType {
 	case dubbo_spec.RESPONSE_VALUE, dubbo_spec.RESPONSE_VALUE_WITH_ATTACHMENTS:
-		msg, ok := message.Data().(iface.Message)
+		msg, ok := getMessage(message.Data())
 		if !ok {
 			return fmt.Errorf("invalid data %v: not hessian2.MessageReader", msg)
 		}
@@ -413,18 +671,18 @@ func (m *DubboCodec) decodeResponseBody(ctx context.Context, header *dubbo_spec.
 			return err
 		}
 		if dubbo_spec.IsAttachmentsPayloadType(payloadType) {
-			if err := processAttachments(decoder, message); err != nil {
+			if err := processAttachments(ctx, decoder, message); err != nil {
 				return err
 			}
 		}
 	// business logic exception
 	case dubbo_spec.RESPONSE_WITH_EXCEPTION, dubbo_spec.RESPONSE_WITH_EXCEPTION_WITH_ATTACHMENTS:
-		exception, err := decoder.Decode()
+		exception, err := decodeException(decoder)
 		if err != nil {
 			return err
 		}
 		if dubbo_spec.IsAttachmentsPayloadType(payloadType) {
-			if err := processAttachments(decoder, message); err != nil {
+			if err := processAttachments(ctx, decoder, message); err != nil {
 				return err
 			}
 		}
@@ -434,7 +692,7 @@ func (m *DubboCodec) decodeResponseBody(ctx context.Context, header *dubbo_spec.
 		return fmt.Errorf("dubbo side exception: %v", exception)
 	case dubbo_spec.RESPONSE_NULL_VALUE, dubbo_spec.RESPONSE_NULL_VALUE_WITH_ATTACHMENTS:
 		if dubbo_spec.IsAttachmentsPayloadType(payloadType) {
-			if err := processAttachments(decoder, message); err != nil {
+			if err := processAttachments(ctx, decoder, message); err != nil {
 				return err
 			}
 		}
@@ -444,35 +702,56 @@ func (m *DubboCodec) decodeResponseBody(ctx context.Context, header *dubbo_spec.
 	return nil
 }
 
-func processAttachments(decoder iface.Decoder, message remote.Message) error {
+func processAttachments(ctx context.Context, decoder iface.Decoder, message remote.Message) error {
 	// decode attachments
-	attachmentsRaw, err := decoder.Decode()
-	if err != nil {
-		return err
-	}
-
-	if attachments, ok := attachmentsRaw.(map[interface{}]interface{}); ok {
-		transStrMap := map[string]string{}
-		transIntMap := map[uint16]string{}
-		for keyRaw, val := range attachments {
-			if key, ok := keyRaw.(string); ok {
-				message.Tags()[key] = val
-				if v, ok := val.(string); ok {
-					transStrMap[key] = v
-				}
+	var attachments map[interface{}]interface{}
+	if err := dubbo_spec.DecodeTo(decoder, &attachments); err != nil {
+		return fmt.Errorf("unsupported attachments: %s", err)
+	}
+
+	transStrMap := map[string]string{}
+	transIntMap := map[uint16]string{}
+	// typed values which could not be carried by TransInfo
+	recvMap := map[string]interface{}{}
+	for keyRaw, val := range attachments {
+		if key, ok := keyRaw.(string); ok {
+			message.Tags()[key] = val
+			recvMap[key] = val
+			if v, ok := val.(string); ok {
+				transStrMap[key] = v
 			}
-			if uint16Key, ok := keyRaw.(uint16); ok {
-				if v, ok := val.(string); ok {
-					transIntMap[uint16Key] = v
-				}
+		}
+		if uint16Key, ok := keyRaw.(uint16); ok {
+			if v, ok := val.(string); ok {
+				transIntMap[uint16Key] = v
 			}
 		}
-		message.TransInfo().PutTransStrInfo(transStrMap)
-		message.TransInfo().PutTransIntInfo(transIntMap)
-		return nil
 	}
+	message.TransInfo().PutTransStrInfo(transStrMap)
+	message.TransInfo().PutTransIntInfo(transIntMap)
+	setRecvAttachments(ctx, message, recvMap)
+	return nil
+}
+
+// decodeException decodes the business logic exception, TypedDecoders decode it as error.
+func decodeException(decoder iface.Decoder) (interface{}, error) {
+	if _, ok := decoder.(iface.TypedDecoder); !ok {
+		return decoder.Decode()
+	}
+	var exception error
+	err := dubbo_spec.DecodeTo(decoder, &exception)
+	return exception, err
+}
 
-	return fmt.Errorf("unsupported attachments: %v", attachmentsRaw)
+// getMessage returns data as iface.Message, the args and results generated by kitex protobuf are adapted.
+func getMessage(data interface{}) (iface.Message, bool) {
+	switch msg := data.(type) {
+	case iface.Message:
+		return msg, true
+	case protobuf.Message:
+		return protobuf.NewMessage(msg), true
+	}
+	return nil, false
 }
 
 func readBody(header *dubbo_spec.DubboHeader, in remote.ByteBuffer) ([]byte, error) {
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/remote/codec"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+// protocolExtraKey is the key of Invocation extra recording whether the request decoded by the detection codec
+// is a dubbo request.
+const protocolExtraKey = "dubbo_detected"
+
+var _ remote.Codec = (*detectionCodec)(nil)
+
+// NewDetectionCodec returns a server-side codec serving dubbo and the protocols supported by defaultCodec on the same
+// port, which helps migrating dubbo consumers to kitex gradually. If defaultCodec is nil, codec.NewDefaultCodec()
+// is used, which serves Thrift and Kitex Protobuf over TTHeader, Framed and Buffered transports.
+// Requests starting with the dubbo magic 0xdabb are decoded by dubboCodec, others are decoded by defaultCodec.
+// Responses are encoded with the codec decoding the request, so the same handler serves both protocols:
+//
+//	svr := greetservice.NewServer(new(GreetServiceImpl),
+//		server.WithCodec(dubbo.NewDetectionCodec(
+//			dubbo.NewDubboCodec(dubbo.WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider")),
+//			nil,
+//		)),
+//	)
+func NewDetectionCodec(dubboCodec *DubboCodec, defaultCodec remote.Codec) remote.Codec {
+	if dubboCodec == nil {
+		panic("Please pass in a valid DubboCodec.")
+	}
+	if defaultCodec == nil {
+		defaultCodec = codec.NewDefaultCodec()
+	}
+	return &detectionCodec{
+		dubboCodec:   dubboCodec,
+		defaultCodec: defaultCodec,
+	}
+}
+
+type detectionCodec struct {
+	dubboCodec   *DubboCodec
+	defaultCodec remote.Codec
+}
+
+func (c *detectionCodec) Encode(ctx context.Context, message remote.Message, out remote.ByteBuffer) error {
+	if isDubboMessage(message) {
+		return c.dubboCodec.Encode(ctx, message, out)
+	}
+	return c.defaultCodec.Encode(ctx, message, out)
+}
+
+func (c *detectionCodec) Decode(ctx context.Context, message remote.Message, in remote.ByteBuffer) error {
+	magic, err := in.Peek(2)
+	if err != nil {
+		return err
+	}
+	isDubbo := magic[0] == dubbo_spec.MAGIC_HIGH && magic[1] == dubbo_spec.MAGIC_LOW
+	// telnet commands are served by DubboCodec if enabled
+	if !isDubbo && c.dubboCodec.opt.Telnet && isTelnetCommand(magic[0]) {
+		isDubbo = true
+	}
+	setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return errors.New("the interface Invocation doesn't implement InvocationSetter")
+	}
+	setter.SetExtra(protocolExtraKey, isDubbo)
+	if isDubbo {
+		return c.dubboCodec.Decode(ctx, message, in)
+	}
+	return c.defaultCodec.Decode(ctx, message, in)
+}
+
+func (c *detectionCodec) Name() string {
+	return c.dubboCodec.Name() + "|" + c.defaultCodec.Name()
+}
+
+// isDubboMessage reports whether message belongs to a dubbo request.
+// Messages not decoded by the detection codec are considered as dubbo messages.
+func isDubboMessage(message remote.Message) bool {
+	isDubbo, detected := detectedProtocol(message)
+	return !detected || isDubbo
+}
+
+// detectedProtocol reports whether message belongs to a dubbo request if it has been decoded by the detection codec.
+func detectedProtocol(message remote.Message) (isDubbo, detected bool) {
+	ri := message.RPCInfo()
+	if ri == nil || ri.Invocation() == nil {
+		return false, false
+	}
+	isDubbo, detected = ri.Invocation().Extra(protocolExtraKey).(bool)
+	return
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockCodec stands for the default codec of kitex, it reads and writes 2 bytes.
+type mockCodec struct{}
+
+func (c *mockCodec) Encode(ctx context.Context, message remote.Message, out remote.ByteBuffer) error {
+	_, err := out.WriteBinary([]byte{0x80, 0x01})
+	return err
+}
+
+func (c *mockCodec) Decode(ctx context.Context, message remote.Message, in remote.ByteBuffer) error {
+	return in.Skip(2)
+}
+
+func (c *mockCodec) Name() string {
+	return "mock"
+}
+
+func TestDetectionCodec(t *testing.T) {
+	codec := NewDetectionCodec(NewDubboCodec(WithJavaClassName(testJavaClassName)), new(mockCodec))
+	svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+
+	encoder := hessian2.NewEncoder()
+	for _, v := range []interface{}{
+		dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, "", EchoMethod, echoTypes, "OK",
+		map[interface{}]interface{}{dubbo_spec.PATH_KEY: testJavaClassName},
+	} {
+		assert.Nil(t, encoder.Encode(v))
+	}
+	body := encoder.Buffer()
+	header := &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		DataLength:      uint32(len(body)),
+	}
+
+	tests := []struct {
+		desc    string
+		request []byte
+		isDubbo bool
+	}{
+		{
+			desc:    "dubbo",
+			request: append(header.EncodeToByteSlice(), body...),
+			isDubbo: true,
+		},
+		{
+			desc:    "thrift",
+			request: []byte{0x80, 0x01},
+			isDubbo: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+			in := remote.NewReaderBuffer(test.request)
+			assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+			assert.Equal(t, 0, in.ReadableLen())
+			assert.Equal(t, test.isDubbo, isDubboMessage(recvMsg))
+
+			sendMsg := remote.NewMessage(nil, svcInfo, ri, recvMsg.MessageType(), remote.Server)
+			out := remote.NewReaderWriterBuffer(1024)
+			assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+			buf, err := out.Bytes()
+			assert.Nil(t, err)
+			if test.isDubbo {
+				assert.Equal(t, []byte{dubbo_spec.MAGIC_HIGH, dubbo_spec.MAGIC_LOW}, buf[:2])
+			} else {
+				assert.Equal(t, []byte{0x80, 0x01}, buf)
+			}
+		})
+	}
+}
+
+type mockSvrTransHandler struct {
+	remote.ServerTransHandler
+}
+
+func (h *mockSvrTransHandler) OnActive(ctx context.Context, conn net.Conn) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *mockSvrTransHandler) Write(ctx context.Context, conn net.Conn, send remote.Message) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *mockSvrTransHandler) Read(ctx context.Context, conn net.Conn, msg remote.Message) (context.Context, error) {
+	return ctx, nil
+}
+
+type mockConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *mockConn) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
+
+func (c *mockConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 20000}
+}
+
+func TestGracefulShutdownWithDetection(t *testing.T) {
+	hdlr := &svrTransHandler{
+		ServerTransHandler: new(mockSvrTransHandler),
+		detection:          true,
+		conns:              make(map[net.Conn]*connState),
+	}
+	dubboConn, thriftConn, idleConn := new(mockConn), new(mockConn), new(mockConn)
+	for _, conn := range []*mockConn{dubboConn, thriftConn, idleConn} {
+		_, err := hdlr.OnActive(context.Background(), conn)
+		assert.Nil(t, err)
+	}
+
+	// requests are decoded by the detection codec, and the thrift one has not been replied
+	for conn, isDubbo := range map[*mockConn]bool{dubboConn: true, thriftConn: false} {
+		ri := rpcinfo.NewRPCInfo(nil, nil, rpcinfo.NewServerInvocation(), nil, nil)
+		ri.Invocation().(rpcinfo.InvocationSetter).SetExtra(protocolExtraKey, isDubbo)
+		_, err := hdlr.Read(context.Background(), conn, remote.NewMessage(nil, nil, ri, remote.Call, remote.Server))
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, hdlr.GracefulShutdown(context.Background()))
+	assert.Equal(t, []byte{dubbo_spec.MAGIC_HIGH, dubbo_spec.MAGIC_LOW}, dubboConn.buf.Bytes()[:2])
+	assert.Equal(t, 0, thriftConn.buf.Len())
+	assert.Equal(t, 0, idleConn.buf.Len())
+}
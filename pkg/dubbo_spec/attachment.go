@@ -32,6 +32,9 @@ const (
 	INTERFACE_KEY = "interface"
 	VERSION_KEY   = "version"
 	TIMEOUT_KEY   = "timeout"
+	GENERIC_KEY   = "generic"
+
+	GENERIC_VALUE_TRUE = "true"
 )
 
 type Attachment = map[interface{}]interface{}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo_spec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+// MOCK_HEARTBEAT_EVENT is the data of heartbeat events for serializations which could not encode null, e.g. protobuf.
+const MOCK_HEARTBEAT_EVENT = "H"
+
+// DecodeTo decodes the next value of decoder into the value pointed to by v.
+// TypedDecoders decode the value according to the type of v, values decoded by other Decoders must be assignable to v.
+func DecodeTo(decoder iface.Decoder, v interface{}) error {
+	if typedDecoder, ok := decoder.(iface.TypedDecoder); ok {
+		return typedDecoder.DecodeTo(v)
+	}
+	val, err := decoder.Decode()
+	if err != nil {
+		return err
+	}
+	dest := reflect.ValueOf(v).Elem()
+	if val == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.Type().AssignableTo(dest.Type()) {
+		return fmt.Errorf("can not decode %T to %s", val, dest.Type())
+	}
+	dest.Set(rv)
+	return nil
+}
+
+// DecodeEvent decodes the data of event packages, the data of heartbeat events is decoded as nil.
+func DecodeEvent(decoder iface.Decoder) (interface{}, error) {
+	if _, ok := decoder.(iface.TypedDecoder); !ok {
+		return decoder.Decode()
+	}
+	var event string
+	if err := DecodeTo(decoder, &event); err != nil {
+		return nil, err
+	}
+	if event == MOCK_HEARTBEAT_EVENT {
+		return nil, nil
+	}
+	return event, nil
+}
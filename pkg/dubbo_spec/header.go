@@ -47,8 +47,11 @@ const (
 	IS_EVENT        = 1
 	EVENT_BIT_SHIFT = 5
 
-	SERIALIZATION_ID_HESSIAN = 2
-	SERIALIZATION_ID_MASK    = 0x1F
+	SERIALIZATION_ID_HESSIAN  = 2
+	SERIALIZATION_ID_FASTJSON = 6
+	SERIALIZATION_ID_GSON     = 16
+	SERIALIZATION_ID_PROTOBUF = 22
+	SERIALIZATION_ID_MASK     = 0x1F
 
 	StatusOK                  StatusCode = 20
 	StatusClientTimeout       StatusCode = 30
@@ -62,10 +65,39 @@ const (
 	StatusServerPoolExhausted StatusCode = 100
 )
 
+// data of dubbo event package
+const (
+	// READONLY_EVENT is sent by dubbo provider before shutting down, consumers should not send new requests to it.
+	READONLY_EVENT = "R"
+	// WRITABLE_EVENT is sent by dubbo provider to cancel READONLY_EVENT.
+	WRITABLE_EVENT = "W"
+)
+
 var ErrInvalidHeader = fmt.Errorf("INVALID HEADER")
 
 type StatusCode uint8
 
+var statusNames = map[StatusCode]string{
+	StatusOK:                  "OK",
+	StatusClientTimeout:       "CLIENT_TIMEOUT",
+	StatusServerTimeout:       "SERVER_TIMEOUT",
+	StatusBadRequest:          "BAD_REQUEST",
+	StatusBadResponse:         "BAD_RESPONSE",
+	StatusServiceNotFound:     "SERVICE_NOT_FOUND",
+	StatusServiceError:        "SERVICE_ERROR",
+	StatusServerError:         "SERVER_ERROR",
+	StatusClientError:         "CLIENT_ERROR",
+	StatusServerPoolExhausted: "SERVER_THREADPOOL_EXHAUSTED_ERROR",
+}
+
+// String returns the name of StatusCode defined in dubbo-java.
+func (s StatusCode) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN_STATUS(%d)", uint8(s))
+}
+
 type DubboHeader struct {
 	IsRequest       bool       // 1 bit
 	IsOneWay        bool       // 1 bit
@@ -50,13 +50,9 @@ func IsAttachmentsPayloadType(typ PayloadType) bool {
 }
 
 func DecodePayloadType(decoder iface.Decoder) (PayloadType, error) {
-	payloadTypeRaw, err := decoder.Decode()
-	if err != nil {
-		return 0, err
-	}
-	payloadTypeInt32, ok := payloadTypeRaw.(int32)
-	if !ok {
-		return 0, fmt.Errorf("dubbo PayloadType decoded failed, got: %v", payloadTypeRaw)
+	var payloadTypeInt32 int32
+	if err := DecodeTo(decoder, &payloadTypeInt32); err != nil {
+		return 0, fmt.Errorf("dubbo PayloadType decoded failed: %s", err)
 	}
 	return PayloadType(payloadTypeInt32), nil
 }
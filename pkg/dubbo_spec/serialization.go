@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo_spec
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+var (
+	serializationsMu sync.RWMutex
+	serializations   = make(map[uint8]iface.Serialization)
+)
+
+// RegisterSerialization registers s so that it could be selected by its ID when decoding and by its name
+// when configuring DubboCodec. It panics if the ID of s is invalid or has been registered.
+func RegisterSerialization(s iface.Serialization) {
+	id := s.ID()
+	if id&SERIALIZATION_ID_MASK != id {
+		panic(fmt.Sprintf("invalid SerializationID %d of %s", id, s.Name()))
+	}
+	serializationsMu.Lock()
+	defer serializationsMu.Unlock()
+	if dup, exists := serializations[id]; exists {
+		panic(fmt.Sprintf("SerializationID %d is registered by both %s and %s", id, dup.Name(), s.Name()))
+	}
+	serializations[id] = s
+}
+
+// GetSerialization returns the Serialization registered with id.
+func GetSerialization(id uint8) (iface.Serialization, bool) {
+	serializationsMu.RLock()
+	defer serializationsMu.RUnlock()
+	s, ok := serializations[id]
+	return s, ok
+}
+
+// GetSerializationByName returns the Serialization registered with name, eg. hessian2, fastjson.
+func GetSerializationByName(name string) (iface.Serialization, bool) {
+	serializationsMu.RLock()
+	defer serializationsMu.RUnlock()
+	for _, s := range serializations {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// javaSerializationNames are the names of serializations shipped with dubbo-java, which are used to describe
+// SerializationIDs that are not registered.
+var javaSerializationNames = map[uint8]string{
+	2:  "hessian2",
+	3:  "java",
+	4:  "compactedjava",
+	6:  "fastjson",
+	7:  "nativejava",
+	8:  "kryo",
+	9:  "fst",
+	10: "native-hessian",
+	11: "avro",
+	12: "protostuff",
+	16: "gson",
+	21: "protobuf-json",
+	22: "protobuf",
+	23: "fastjson2",
+	25: "kryo2",
+	31: "msgpack",
+}
+
+// GetSerializationName returns the name of the serialization identified by id, whether it has been registered or not.
+// "unknown" is returned if the id is not known to dubbo-java either.
+func GetSerializationName(id uint8) string {
+	if s, ok := GetSerialization(id); ok {
+		return s.Name()
+	}
+	if name, ok := javaSerializationNames[id]; ok {
+		return name
+	}
+	return "unknown"
+}
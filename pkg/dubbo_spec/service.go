@@ -57,14 +57,8 @@ func (svc *Service) Decode(decoder iface.Decoder) error {
 
 // decodeString decodes dubbo Service string field
 func decodeString(decoder iface.Decoder, target *string, targetName string) error {
-	strRaw, err := decoder.Decode()
-	if err != nil {
-		return err
-	}
-	str, ok := strRaw.(string)
-	if !ok {
-		return fmt.Errorf("decode dubbo Service field %s failed, got %v", targetName, strRaw)
+	if err := DecodeTo(decoder, target); err != nil {
+		return fmt.Errorf("decode dubbo Service field %s failed: %s", targetName, err)
 	}
-	*target = str
 	return nil
 }
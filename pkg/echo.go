@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+const (
+	// EchoMethod is the method name of org.apache.dubbo.rpc.service.EchoService#$echo,
+	// which is used by dubbo consumers and dubbo-admin to check the liveness of a provider interface.
+	EchoMethod = "$echo"
+
+	echoTypes = "Ljava/lang/Object;"
+	// echoExtraKey is the key of Invocation extra storing the echo result.
+	echoExtraKey = "dubbo_echo_result"
+)
+
+// echoResult wraps the argument of $echo, so that nil argument could be distinguished from absence.
+type echoResult struct {
+	value interface{}
+}
+
+func isEcho(method, types string) bool {
+	return method == EchoMethod && types == echoTypes
+}
+
+// decodeEchoRequest decodes the argument of $echo and marks the message as Heartbeat.
+// Like EchoFilter of dubbo-java, kitex server replies Heartbeat with DubboCodec.Encode directly,
+// the handler is not invoked and $echo need not be declared in IDL.
+func decodeEchoRequest(ctx context.Context, decoder iface.Decoder, message remote.Message) error {
+	var arg interface{}
+	if err := dubbo_spec.DecodeTo(decoder, &arg); err != nil {
+		return err
+	}
+	if err := processAttachments(ctx, decoder, message); err != nil {
+		return err
+	}
+	setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return errors.New("the interface Invocation doesn't implement InvocationSetter")
+	}
+	setter.SetExtra(echoExtraKey, &echoResult{value: arg})
+	message.SetMessageType(remote.Heartbeat)
+	return nil
+}
+
+// getEchoResult returns the echo result recorded by decodeEchoRequest.
+func getEchoResult(message remote.Message) (*echoResult, bool) {
+	echo, ok := message.RPCInfo().Invocation().Extra(echoExtraKey).(*echoResult)
+	return echo, ok
+}
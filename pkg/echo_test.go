@@ -0,0 +1,106 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcho(t *testing.T) {
+	const javaClassName = "org.cloudwego.kitex.samples.api.GreetProvider"
+	codec := NewDubboCodec(WithJavaClassName(javaClassName))
+
+	tests := []struct {
+		desc    string
+		arg     interface{}
+		payload dubbo_spec.PayloadType
+	}{
+		{
+			desc:    "string",
+			arg:     "OK",
+			payload: dubbo_spec.RESPONSE_VALUE,
+		},
+		{
+			desc:    "null",
+			arg:     nil,
+			payload: dubbo_spec.RESPONSE_NULL_VALUE,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			// build $echo request sent by dubbo consumer
+			encoder := hessian2.NewEncoder()
+			for _, v := range []interface{}{
+				dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, javaClassName, "", EchoMethod, echoTypes, test.arg,
+				map[interface{}]interface{}{dubbo_spec.PATH_KEY: javaClassName},
+			} {
+				assert.Nil(t, encoder.Encode(v))
+			}
+			body := encoder.Buffer()
+			header := &dubbo_spec.DubboHeader{
+				IsRequest:       true,
+				SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+				RequestID:       1,
+				DataLength:      uint32(len(body)),
+			}
+			in := remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))
+
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+			recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+			assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+			assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+
+			// kitex server replies Heartbeat directly
+			sendMsg := remote.NewMessage(nil, svcInfo, ri, remote.Heartbeat, remote.Server)
+			out := remote.NewReaderWriterBuffer(1024)
+			assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+			buf, err := out.Bytes()
+			assert.Nil(t, err)
+
+			respHeader := new(dubbo_spec.DubboHeader)
+			assert.Nil(t, respHeader.DecodeFromByteSlice(buf[:dubbo_spec.HEADER_SIZE]))
+			assert.False(t, respHeader.IsRequest)
+			assert.False(t, respHeader.IsEvent)
+			assert.Equal(t, dubbo_spec.StatusOK, respHeader.Status)
+			assert.Equal(t, uint64(1), respHeader.RequestID)
+
+			decoder := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:])
+			payloadType, err := dubbo_spec.DecodePayloadType(decoder)
+			assert.Nil(t, err)
+			assert.Equal(t, test.payload, payloadType)
+			if test.arg != nil {
+				val, err := decoder.Decode()
+				assert.Nil(t, err)
+				assert.Equal(t, test.arg, val)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sync/atomic"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+)
+
+// eventRequestID is used to generate RequestID of event packages sent by DubboCodec proactively.
+// It starts from 1 << 32 so that it would not conflict with the SeqID(int32) of kitex.
+var eventRequestID uint64 = 1 << 32
+
+func nextEventRequestID() uint64 {
+	return atomic.AddUint64(&eventRequestID, 1)
+}
+
+// buildEventRequest builds an entire dubbo event request package whose body is data.
+// For heartbeat, data is nil. For READONLY_EVENT and WRITABLE_EVENT, data is the event string.
+func buildEventRequest(data interface{}, twoWay bool) (pkg []byte, requestID uint64, err error) {
+	encoder := hessian2.NewEncoder()
+	if err := encoder.Encode(data); err != nil {
+		return nil, 0, err
+	}
+	payload := encoder.Buffer()
+
+	requestID = nextEventRequestID()
+	header := &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		IsOneWay:        !twoWay,
+		IsEvent:         true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		RequestID:       requestID,
+		DataLength:      uint32(len(payload)),
+	}
+	return append(header.EncodeToByteSlice(), payload...), requestID, nil
+}
+
+// buildHeartbeatResponse builds an entire dubbo heartbeat response package replying the request specified by requestID.
+func buildHeartbeatResponse(requestID uint64) ([]byte, error) {
+	encoder := hessian2.NewEncoder()
+	if err := encoder.Encode(nil); err != nil {
+		return nil, err
+	}
+	payload := encoder.Buffer()
+
+	header := &dubbo_spec.DubboHeader{
+		IsEvent:         true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		Status:          dubbo_spec.StatusOK,
+		RequestID:       requestID,
+		DataLength:      uint32(len(payload)),
+	}
+	return append(header.EncodeToByteSlice(), payload...), nil
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"testing"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEventRequest(t *testing.T) {
+	pkg, requestID, err := buildEventRequest(dubbo_spec.READONLY_EVENT, false)
+	assert.Nil(t, err)
+
+	header := new(dubbo_spec.DubboHeader)
+	assert.Nil(t, header.DecodeFromByteSlice(pkg[:dubbo_spec.HEADER_SIZE]))
+	assert.True(t, header.IsRequest)
+	assert.True(t, header.IsEvent)
+	assert.True(t, header.IsOneWay)
+	assert.Equal(t, requestID, header.RequestID)
+	assert.Equal(t, len(pkg)-dubbo_spec.HEADER_SIZE, int(header.DataLength))
+
+	event, err := hessian2.NewDecoder(pkg[dubbo_spec.HEADER_SIZE:]).Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, dubbo_spec.READONLY_EVENT, event)
+
+	pkg, nextRequestID, err := buildEventRequest(nil, true)
+	assert.Nil(t, err)
+	assert.NotEqual(t, requestID, nextRequestID)
+	assert.Equal(t, []byte{hessian2.NULL}, pkg[dubbo_spec.HEADER_SIZE:])
+}
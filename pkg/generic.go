@@ -0,0 +1,275 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/client/callopt"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+const (
+	// GenericServiceName is the kitex ServiceName of the generic service.
+	GenericServiceName = "GenericService"
+	// GenericInvokeMethod is the method name of org.apache.dubbo.rpc.service.GenericService#$invoke.
+	GenericInvokeMethod = "$invoke"
+	// GenericInvokeAsyncMethod is the method name of org.apache.dubbo.rpc.service.GenericService#$invokeAsync,
+	// which shares the same arguments with $invoke.
+	GenericInvokeAsyncMethod = "$invokeAsync"
+
+	genericInvokeTypes = "Ljava/lang/String;[Ljava/lang/String;[Ljava/lang/Object;"
+)
+
+// genericInvokeAnnotation specifies the parameter types of GenericService#$invoke(String, String[], Object[]).
+var genericInvokeAnnotation = hessian2.NewMethodAnnotation(map[string][]string{
+	hessian2.HESSIAN_ARGS_TYPE_TAG: {"String,String[],Object[]"},
+})
+
+var genericServiceInfo = newGenericServiceInfo()
+
+func newGenericServiceInfo() *serviceinfo.ServiceInfo {
+	return &serviceinfo.ServiceInfo{
+		ServiceName: GenericServiceName,
+		Methods: map[string]serviceinfo.MethodInfo{
+			GenericInvokeMethod: serviceinfo.NewMethodInfo(
+				nil,
+				func() interface{} { return new(GenericInvokeArgs) },
+				func() interface{} { return new(GenericInvokeResult) },
+				false,
+			),
+		},
+	}
+}
+
+// GenericInvokeArgs is the arguments of GenericService#$invoke.
+type GenericInvokeArgs struct {
+	// Method is the name of the method to be invoked.
+	Method string
+	// ParameterTypes are the java types of the method parameters, e.g. "java.lang.String", "int",
+	// "org.cloudwego.kitex.samples.api.GreetRequest".
+	ParameterTypes []string
+	// Args are the method arguments, POJOs could be passed as map[string]interface{} which keys are the field names.
+	Args []interface{}
+}
+
+func (p *GenericInvokeArgs) Encode(e iface.Encoder) error {
+	if err := e.Encode(p.Method); err != nil {
+		return err
+	}
+	parameterTypes := p.ParameterTypes
+	if parameterTypes == nil {
+		parameterTypes = []string{}
+	}
+	if err := e.Encode(parameterTypes); err != nil {
+		return err
+	}
+	args := p.Args
+	if args == nil {
+		args = []interface{}{}
+	}
+	return e.Encode(args)
+}
+
+func (p *GenericInvokeArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Method); err != nil {
+		return fmt.Errorf("invalid $invoke method: %T, %s", v, err)
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.ParameterTypes); err != nil {
+		return fmt.Errorf("invalid $invoke parameter types: %T, %s", v, err)
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Args); err != nil {
+		return fmt.Errorf("invalid $invoke args: %T, %s", v, err)
+	}
+	return nil
+}
+
+func (p *GenericInvokeArgs) GetFirstArgument() interface{} {
+	return p.Method
+}
+
+// GenericInvokeResult is the result of GenericService#$invoke.
+type GenericInvokeResult struct {
+	Success interface{}
+}
+
+func (p *GenericInvokeResult) Encode(e iface.Encoder) error {
+	return e.Encode(p.Success)
+}
+
+func (p *GenericInvokeResult) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	p.Success = toGenericValue(v)
+	return nil
+}
+
+func (p *GenericInvokeResult) GetResult() interface{} {
+	return p.Success
+}
+
+// toGenericValue converts maps decoded by hessian2 to map[string]interface{} recursively if all the keys
+// are strings, so that the result could be marshaled to json directly.
+// New containers are built for the converted values, v is not modified.
+func toGenericValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		if !hasStringKeys(val) {
+			res := make(map[interface{}]interface{}, len(val))
+			for k, elem := range val {
+				res[k] = toGenericValue(elem)
+			}
+			return res
+		}
+		res := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			res[k.(string)] = toGenericValue(elem)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, elem := range val {
+			res[i] = toGenericValue(elem)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+func hasStringKeys(m map[interface{}]interface{}) bool {
+	for k := range m {
+		if _, ok := k.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isGenericInvoke reports whether the request is a GenericService#$invoke or GenericService#$invokeAsync call.
+func isGenericInvoke(method, types string) bool {
+	return (method == GenericInvokeMethod || method == GenericInvokeAsyncMethod) && types == genericInvokeTypes
+}
+
+// realizeGenericArgs converts the $invoke arguments in generic form to the fields of the generated arg struct.
+func realizeGenericArgs(arg iface.Message, args []interface{}) error {
+	val := reflect.ValueOf(arg).Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("invalid arg type: %T", arg)
+	}
+	if val.NumField() != len(args) {
+		return fmt.Errorf("$invoke args number mismatch, expected: %d, got: %d", val.NumField(), len(args))
+	}
+	for i, genericArg := range args {
+		field, err := hessian2.Realize(genericArg, val.Field(i).Type())
+		if err != nil {
+			return fmt.Errorf("realize $invoke arg %d failed: %s", i, err)
+		}
+		val.Field(i).Set(field)
+	}
+	return nil
+}
+
+// markGenericResponse records that the response of the request should be returned in generic form.
+// RPCInfo is shared by the request and the response on the server side.
+func markGenericResponse(message remote.Message) error {
+	from := rpcinfo.AsMutableEndpointInfo(message.RPCInfo().From())
+	if from == nil {
+		return fmt.Errorf("mark generic response failed: caller EndpointInfo is immutable")
+	}
+	return from.SetTag(dubbo_spec.GENERIC_KEY, dubbo_spec.GENERIC_VALUE_TRUE)
+}
+
+func isGenericResponse(message remote.Message) bool {
+	from := message.RPCInfo().From()
+	if from == nil {
+		return false
+	}
+	generic, _ := from.Tag(dubbo_spec.GENERIC_KEY)
+	return generic == dubbo_spec.GENERIC_VALUE_TRUE
+}
+
+// generalizeResult converts the success value of the generated result struct to generic form.
+func generalizeResult(data iface.Message) iface.Message {
+	result, ok := data.(interface{ GetResult() interface{} })
+	if !ok {
+		return data
+	}
+	return &GenericInvokeResult{Success: hessian2.Generalize(result.GetResult())}
+}
+
+// GenericClient invokes dubbo services by GenericService#$invoke without generated code.
+// The InterfaceName of the service is specified by WithJavaClassName of the DubboCodec.
+type GenericClient interface {
+	// GenericInvoke invokes method with java parameterTypes and args. POJOs in the result are returned
+	// as map[string]interface{} which contains the "class" key if the provider enables generic serialization.
+	GenericInvoke(ctx context.Context, method string, parameterTypes []string, args []interface{}, callOptions ...callopt.Option) (interface{}, error)
+}
+
+// NewGenericClient creates a GenericClient, DubboCodec should be specified by client.WithCodec.
+func NewGenericClient(destService string, opts ...client.Option) (GenericClient, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+	options = append(options, opts...)
+
+	kc, err := client.NewClient(genericServiceInfo, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &genericClient{c: kc}, nil
+}
+
+type genericClient struct {
+	c client.Client
+}
+
+func (g *genericClient) GenericInvoke(ctx context.Context, method string, parameterTypes []string, args []interface{}, callOptions ...callopt.Option) (interface{}, error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	_args := &GenericInvokeArgs{
+		Method:         method,
+		ParameterTypes: parameterTypes,
+		Args:           args,
+	}
+	var _result GenericInvokeResult
+	if err := g.c.Call(ctx, GenericInvokeMethod, _args, &_result); err != nil {
+		return nil, err
+	}
+	return _result.GetResult(), nil
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericInvokeArgs(t *testing.T) {
+	var mc hessian2.MethodCache
+	types, err := mc.GetTypes(&GenericInvokeArgs{}, genericInvokeAnnotation)
+	assert.Nil(t, err)
+	assert.Equal(t, "Ljava/lang/String;[Ljava/lang/String;[Ljava/lang/Object;", types)
+
+	args := &GenericInvokeArgs{
+		Method:         "GreetWithStruct",
+		ParameterTypes: []string{"org.cloudwego.kitex.samples.api.GreetRequest"},
+		Args:           []interface{}{map[string]interface{}{"req": "world"}},
+	}
+	encoder := hessian2.NewEncoder()
+	assert.Nil(t, args.Encode(encoder))
+
+	decoded := new(GenericInvokeArgs)
+	assert.Nil(t, decoded.Decode(hessian2.NewDecoder(encoder.Buffer())))
+	assert.Equal(t, args.Method, decoded.Method)
+	assert.Equal(t, args.ParameterTypes, decoded.ParameterTypes)
+	assert.Equal(t, 1, len(decoded.Args))
+	assert.Equal(t, "world", decoded.Args[0].(map[interface{}]interface{})["req"])
+}
+
+func TestToGenericValue(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "primitive",
+			input:    int32(1),
+			expected: int32(1),
+		},
+		{
+			desc: "POJO",
+			input: map[interface{}]interface{}{
+				"class": "org.cloudwego.kitex.samples.api.GreetResponse",
+				"resp":  "hello world",
+			},
+			expected: map[string]interface{}{
+				"class": "org.cloudwego.kitex.samples.api.GreetResponse",
+				"resp":  "hello world",
+			},
+		},
+		{
+			desc: "nested",
+			input: []interface{}{
+				map[interface{}]interface{}{
+					"list": []interface{}{map[interface{}]interface{}{"key": "val"}},
+				},
+			},
+			expected: []interface{}{
+				map[string]interface{}{
+					"list": []interface{}{map[string]interface{}{"key": "val"}},
+				},
+			},
+		},
+		{
+			desc: "non-string keys",
+			input: map[interface{}]interface{}{
+				int32(1): map[interface{}]interface{}{"key": "val"},
+			},
+			expected: map[interface{}]interface{}{
+				int32(1): map[string]interface{}{"key": "val"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			input := fmt.Sprintf("%#v", test.input)
+			assert.Equal(t, test.expected, toGenericValue(test.input))
+			// the input is not modified
+			assert.Equal(t, input, fmt.Sprintf("%#v", test.input))
+		})
+	}
+}
+
+type genericTestArgs struct {
+	Req  *genericTestReq
+	Size int32
+}
+
+func (p *genericTestArgs) Encode(e iface.Encoder) error {
+	return nil
+}
+
+func (p *genericTestArgs) Decode(d iface.Decoder) error {
+	return nil
+}
+
+type genericTestReq struct {
+	Req string
+}
+
+func (*genericTestReq) JavaClassName() string {
+	return "org.cloudwego.kitex.samples.api.GreetRequest"
+}
+
+type genericTestResult struct {
+	Success *genericTestReq
+}
+
+func (p *genericTestResult) Encode(e iface.Encoder) error {
+	return e.Encode(p.Success)
+}
+
+func (p *genericTestResult) Decode(d iface.Decoder) error {
+	return nil
+}
+
+func (p *genericTestResult) GetResult() interface{} {
+	return p.Success
+}
+
+func TestIsGenericInvoke(t *testing.T) {
+	assert.True(t, isGenericInvoke(GenericInvokeMethod, genericInvokeTypes))
+	assert.True(t, isGenericInvoke(GenericInvokeAsyncMethod, genericInvokeTypes))
+	// a user-defined method named $invoke
+	assert.False(t, isGenericInvoke(GenericInvokeMethod, "Ljava/lang/String;"))
+	assert.False(t, isGenericInvoke("Greet", genericInvokeTypes))
+}
+
+func TestRealizeGenericArgs(t *testing.T) {
+	arg := new(genericTestArgs)
+	err := realizeGenericArgs(arg, []interface{}{
+		map[interface{}]interface{}{"class": "org.cloudwego.kitex.samples.api.GreetRequest", "req": "world"},
+		int32(1),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &genericTestArgs{Req: &genericTestReq{Req: "world"}, Size: 1}, arg)
+
+	err = realizeGenericArgs(new(genericTestArgs), []interface{}{"world"})
+	assert.NotNil(t, err)
+}
+
+func TestGeneralizeResult(t *testing.T) {
+	result := generalizeResult(&genericTestResult{Success: &genericTestReq{Req: "hello world"}})
+	assert.Equal(t, &GenericInvokeResult{
+		Success: map[string]interface{}{
+			"class": "org.cloudwego.kitex.samples.api.GreetRequest",
+			"req":   "hello world",
+		},
+	}, result)
+}
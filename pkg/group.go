@@ -0,0 +1,147 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+// ServiceKey identifies a dubbo service by InterfaceName, group and version.
+type ServiceKey struct {
+	InterfaceName string
+	Group         string
+	Version       string
+}
+
+// String returns the service key in the form of dubbo-java, e.g. "group/InterfaceName:version".
+func (k ServiceKey) String() string {
+	key := k.InterfaceName
+	if k.Group != "" {
+		key = k.Group + "/" + key
+	}
+	if k.Version != "" {
+		key += ":" + k.Version
+	}
+	return key
+}
+
+// applyServiceKeys merges the InterfaceNames configured by WithServiceKeys into JavaClassNames and ServiceNames,
+// and builds the dispatching table of the InterfaceNames served by multiple groups or versions.
+// kitex services configured by WithJavaClassNames with such InterfaceNames serve the default group and version.
+func (o *Options) applyServiceKeys() {
+	javaClassNames := make(map[string]string, len(o.JavaClassNames)+len(o.ServiceKeys))
+	for svcName, name := range o.JavaClassNames {
+		javaClassNames[svcName] = name
+	}
+	serviceNames := make(map[string]string, len(o.ServiceNames)+len(o.ServiceKeys))
+	for name, svcName := range o.ServiceNames {
+		serviceNames[name] = svcName
+	}
+	o.KeyedInterfaces = make(map[string]bool)
+	for svcName, key := range o.ServiceKeys {
+		if name, ok := javaClassNames[svcName]; ok && name != key.InterfaceName {
+			panic(fmt.Sprintf("kitex service %s is configured with both JavaClassName %s and %s.", svcName, name, key.InterfaceName))
+		}
+		javaClassNames[svcName] = key.InterfaceName
+		o.KeyedInterfaces[key.InterfaceName] = true
+	}
+
+	svcNames := make([]string, 0, len(javaClassNames))
+	for svcName := range javaClassNames {
+		svcNames = append(svcNames, svcName)
+	}
+	sort.Strings(svcNames)
+	o.KeyedServiceNames = make(map[string]string)
+	for _, svcName := range svcNames {
+		name := javaClassNames[svcName]
+		if !o.KeyedInterfaces[name] {
+			continue
+		}
+		key := o.ServiceKeys[svcName]
+		key.InterfaceName = name
+		if dup, exists := o.KeyedServiceNames[key.String()]; exists {
+			panic(fmt.Sprintf("dubbo service %s is configured for both kitex service %s and %s.", key, dup, svcName))
+		}
+		o.KeyedServiceNames[key.String()] = svcName
+		// telnet commands address the service of the default group and version first
+		if _, exists := serviceNames[name]; !exists || key.Group == "" && key.Version == "" {
+			serviceNames[name] = svcName
+		}
+	}
+	o.JavaClassNames = javaClassNames
+	o.ServiceNames = serviceNames
+}
+
+// peekGroup returns the group attachment of the request, which is decoded after the arguments, and the decoder
+// to read the arguments and attachments with the types of kitex. decoder should be positioned after the parameter
+// types, so that the Service decoded before is passed through without being decoded again:
+//   - the arguments and attachments are decoded only once by untyped decoders and replayed by the returned decoder.
+//   - typed decoders skip the arguments without their types on a copy, and decoder itself is returned.
+func peekGroup(decoder iface.Decoder, types string) (string, iface.Decoder, error) {
+	rest := decoder
+	var values []interface{}
+	if cloneable, ok := decoder.(cloneableDecoder); ok {
+		decoder = cloneable.Clone()
+	}
+	_, typed := decoder.(iface.TypedDecoder)
+	for range javaTypeNames(types) {
+		var arg interface{}
+		if err := dubbo_spec.DecodeTo(decoder, &arg); err != nil {
+			return "", nil, err
+		}
+		values = append(values, arg)
+	}
+	var attachments map[interface{}]interface{}
+	if err := dubbo_spec.DecodeTo(decoder, &attachments); err != nil {
+		return "", nil, fmt.Errorf("unsupported attachments: %s", err)
+	}
+	group, _ := attachments[dubbo_spec.GROUP_KEY].(string)
+	if typed {
+		if rest == decoder {
+			return "", nil, fmt.Errorf("typed decoder %T does not support Clone", decoder)
+		}
+		return group, rest, nil
+	}
+	return group, &decodedValues{values: append(values, attachments)}, nil
+}
+
+// cloneableDecoder is implemented by decoders which could be copied at the current position, e.g. the protobuf one.
+type cloneableDecoder interface {
+	Clone() iface.Decoder
+}
+
+// decodedValues replays the values decoded in advance.
+type decodedValues struct {
+	values []interface{}
+}
+
+func (d *decodedValues) Decode() (interface{}, error) {
+	if len(d.values) == 0 {
+		return nil, errors.New("no more values decoded in advance")
+	}
+	v := d.values[0]
+	d.values = d.values[1:]
+	return v, nil
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/kitex-contrib/codec-dubbo/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// groupTestArgs is the same as the one generated by kitex for GreetService.Greet(req string, size i32) string.
+type groupTestArgs struct {
+	Req  string
+	Size int32
+}
+
+func (p *groupTestArgs) Encode(e iface.Encoder) error {
+	if err := e.Encode(p.Req); err != nil {
+		return err
+	}
+	return e.Encode(p.Size)
+}
+
+func (p *groupTestArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Req); err != nil {
+		return err
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Size)
+}
+
+func newGroupTestServiceInfo(svcName string) *serviceinfo.ServiceInfo {
+	return &serviceinfo.ServiceInfo{
+		ServiceName: svcName,
+		Methods: map[string]serviceinfo.MethodInfo{
+			"Greet": serviceinfo.NewMethodInfo(nil,
+				func() interface{} { return new(groupTestArgs) },
+				func() interface{} { return new(groupTestArgs) },
+				false,
+			),
+		},
+	}
+}
+
+func TestServiceKey(t *testing.T) {
+	assert.Equal(t, testJavaClassName, ServiceKey{InterfaceName: testJavaClassName}.String())
+	assert.Equal(t, "g1/"+testJavaClassName+":1.0.0",
+		ServiceKey{InterfaceName: testJavaClassName, Group: "g1", Version: "1.0.0"}.String())
+}
+
+func TestWithServiceKeys(t *testing.T) {
+	o := newOptions([]Option{
+		WithJavaClassNames(map[string]string{
+			"GreetService":     testJavaClassName,
+			"GreetEnumService": "org.cloudwego.kitex.samples.api.GreetEnumProvider",
+		}),
+		WithServiceKeys(map[string]ServiceKey{
+			"GreetServiceV1": {InterfaceName: testJavaClassName, Version: "1.0.0"},
+			"GreetServiceG1": {InterfaceName: testJavaClassName, Group: "g1"},
+		}),
+	})
+	assert.Equal(t, testJavaClassName, o.JavaClassNames["GreetServiceV1"])
+	assert.Equal(t, testJavaClassName, o.JavaClassNames["GreetServiceG1"])
+	assert.Equal(t, "GreetService", o.ServiceNames[testJavaClassName])
+	assert.Equal(t, map[string]string{
+		testJavaClassName:            "GreetService",
+		testJavaClassName + ":1.0.0": "GreetServiceV1",
+		"g1/" + testJavaClassName:    "GreetServiceG1",
+	}, o.KeyedServiceNames)
+	assert.True(t, o.KeyedInterfaces[testJavaClassName])
+	assert.False(t, o.KeyedInterfaces["org.cloudwego.kitex.samples.api.GreetEnumProvider"])
+
+	assert.Panics(t, func() {
+		WithServiceKeys(map[string]ServiceKey{"GreetService": {Version: "1.0.0"}})
+	})
+	// duplicate group and version
+	assert.Panics(t, func() {
+		newOptions([]Option{WithServiceKeys(map[string]ServiceKey{
+			"GreetServiceV1": {InterfaceName: testJavaClassName, Version: "1.0.0"},
+			"GreetServiceV2": {InterfaceName: testJavaClassName, Version: "1.0.0"},
+		})})
+	})
+	// conflict with WithJavaClassNames
+	assert.Panics(t, func() {
+		newOptions([]Option{
+			WithJavaClassNames(map[string]string{"GreetService": "org.cloudwego.kitex.samples.api.GreetEnumProvider"}),
+			WithServiceKeys(map[string]ServiceKey{"GreetService": {InterfaceName: testJavaClassName}}),
+		})
+	})
+}
+
+func TestGroupVersionDispatch(t *testing.T) {
+	codec := NewDubboCodec(
+		WithJavaClassNames(map[string]string{"GreetService": testJavaClassName}),
+		WithServiceKeys(map[string]ServiceKey{
+			"GreetServiceV1": {InterfaceName: testJavaClassName, Version: "1.0.0"},
+			"GreetServiceG1": {InterfaceName: testJavaClassName, Group: "g1", Version: "1.0.0"},
+		}),
+	)
+	svcSearchMap := make(map[string]*serviceinfo.ServiceInfo)
+	for _, svcName := range []string{"GreetService", "GreetServiceV1", "GreetServiceG1"} {
+		svcSearchMap[remote.BuildMultiServiceKey(svcName, "Greet")] = newGroupTestServiceInfo(svcName)
+	}
+
+	tests := []struct {
+		desc     string
+		group    string
+		version  string
+		expected string
+	}{
+		{desc: "default", expected: "GreetService"},
+		{desc: "version", version: "1.0.0", expected: "GreetServiceV1"},
+		{desc: "group and version", group: "g1", version: "1.0.0", expected: "GreetServiceG1"},
+		{desc: "unknown group", group: "g2", version: "1.0.0"},
+		{desc: "unknown version", version: "2.0.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			attachments := map[interface{}]interface{}{dubbo_spec.PATH_KEY: testJavaClassName}
+			if test.group != "" {
+				attachments[dubbo_spec.GROUP_KEY] = test.group
+			}
+			encoder := hessian2.NewEncoder()
+			for _, v := range []interface{}{
+				dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, test.version, "Greet", "Ljava/lang/String;I",
+				"world", int32(1), attachments,
+			} {
+				assert.Nil(t, encoder.Encode(v))
+			}
+			body := encoder.Buffer()
+			header := &dubbo_spec.DubboHeader{
+				IsRequest:       true,
+				SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+				DataLength:      uint32(len(body)),
+			}
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			recvMsg := remote.NewMessageWithNewer(nil, svcSearchMap, ri, remote.Call, remote.Server, false)
+			err := codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...)))
+			assert.Nil(t, err)
+			if test.expected == "" {
+				// the request is rejected with the status without invoking the handler
+				assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+				rejection, _ := getRejection(recvMsg)
+				assert.Equal(t, dubbo_spec.StatusServiceNotFound, getRejectionStatus(rejection))
+				return
+			}
+			assert.Equal(t, test.expected, recvMsg.ServiceInfo().ServiceName)
+			assert.Equal(t, &groupTestArgs{Req: "world", Size: 1}, recvMsg.Data())
+		})
+	}
+}
+
+func TestUnknownServiceKeyKeepsConnection(t *testing.T) {
+	conn := runStatusTestServer(t, NewDubboCodec(
+		WithServiceKeys(map[string]ServiceKey{
+			"GreetService": {InterfaceName: testJavaClassName, Group: "g1", Version: "1.0.0"},
+		}),
+	))
+	tests := []struct {
+		desc    string
+		group   string
+		version string
+	}{
+		{desc: "unknown group", group: "g2", version: "1.0.0"},
+		{desc: "unknown version", group: "g1", version: "2.0.0"},
+	}
+	for i, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			requestID := uint64(2*i + 1)
+			writeStatusTestRequest(t, conn, dubbo_spec.DubboHeader{RequestID: requestID},
+				dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, test.version, "Greet", "Ljava/lang/String;",
+				"world", map[interface{}]interface{}{dubbo_spec.GROUP_KEY: test.group})
+			header, _ := readStatusTestResponse(t, conn)
+			assert.Equal(t, requestID, header.RequestID)
+			assert.Equal(t, dubbo_spec.StatusServiceNotFound, header.Status)
+
+			// the connection is still served
+			writeStatusTestRequest(t, conn, dubbo_spec.DubboHeader{RequestID: requestID + 1},
+				dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName, "1.0.0", EchoMethod, echoTypes,
+				"hello", map[interface{}]interface{}{dubbo_spec.GROUP_KEY: "g1"})
+			header, body := readStatusTestResponse(t, conn)
+			assert.Equal(t, requestID+1, header.RequestID)
+			assert.Equal(t, dubbo_spec.StatusOK, header.Status)
+			decoder := hessian2.NewDecoder(body)
+			payloadType, err := decoder.Decode()
+			assert.Nil(t, err)
+			assert.Equal(t, int32(dubbo_spec.RESPONSE_VALUE), payloadType)
+			echo, err := decoder.Decode()
+			assert.Nil(t, err)
+			assert.Equal(t, "hello", echo)
+		})
+	}
+}
+
+func TestPeekGroup(t *testing.T) {
+	attachments := map[interface{}]interface{}{dubbo_spec.PATH_KEY: testJavaClassName, dubbo_spec.GROUP_KEY: "g1"}
+	t.Run("hessian2", func(t *testing.T) {
+		encoder := hessian2.NewEncoder()
+		for _, v := range []interface{}{"Ljava/lang/String;I", "world", int32(1), attachments} {
+			assert.Nil(t, encoder.Encode(v))
+		}
+		decoder := hessian2.NewDecoder(encoder.Buffer())
+		var types string
+		assert.Nil(t, dubbo_spec.DecodeTo(decoder, &types))
+
+		group, rest, err := peekGroup(decoder, types)
+		assert.Nil(t, err)
+		assert.Equal(t, "g1", group)
+		// the values decoded in advance are replayed
+		args := new(groupTestArgs)
+		assert.Nil(t, args.Decode(rest))
+		assert.Equal(t, &groupTestArgs{Req: "world", Size: 1}, args)
+		var decoded map[interface{}]interface{}
+		assert.Nil(t, dubbo_spec.DecodeTo(rest, &decoded))
+		assert.Equal(t, attachments, decoded)
+		_, err = rest.Decode()
+		assert.NotNil(t, err)
+	})
+	t.Run("protobuf", func(t *testing.T) {
+		encoder := protobuf.NewEncoder()
+		for _, v := range []interface{}{"Lcom/google/protobuf/StringValue;", wrapperspb.String("world"), attachments} {
+			assert.Nil(t, encoder.Encode(v))
+		}
+		decoder := protobuf.NewDecoder(encoder.Buffer())
+		var types string
+		assert.Nil(t, dubbo_spec.DecodeTo(decoder, &types))
+
+		group, rest, err := peekGroup(decoder, types)
+		assert.Nil(t, err)
+		assert.Equal(t, "g1", group)
+		// the arguments are decoded with their types from the original position
+		assert.Equal(t, iface.Decoder(decoder), rest)
+		arg := new(wrapperspb.StringValue)
+		assert.Nil(t, dubbo_spec.DecodeTo(rest, arg))
+		assert.Equal(t, "world", arg.GetValue())
+	})
+}
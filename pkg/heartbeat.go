@@ -0,0 +1,493 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/netpoll"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+const (
+	defaultHeartbeatThreshold = 3
+	// maxHeartbeatTimeout limits the time waiting for the heartbeat reply, rpc calls getting the connection
+	// from pool would wait for the heartbeat in progress.
+	maxHeartbeatTimeout = time.Second
+	// idleCheckInterval is the max interval of processing the events received on idle connections.
+	idleCheckInterval = time.Second
+)
+
+var errHeartbeatTimeout = errors.New("dubbo heartbeat reply timeout")
+
+// ConnPool wraps inner with the client-side keepalive configured by WithHeartbeat, events sent by providers on idle
+// connections are also processed by the wrapped pool when they arrive (see Loadbalancer). inner should be a long connection pool, e.g.
+//
+//	codec := dubbo.NewDubboCodec(
+//		dubbo.WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"),
+//		dubbo.WithHeartbeat(30*time.Second, 3),
+//	)
+//	cli, err := greetservice.NewClient("helloworld",
+//		client.WithCodec(codec),
+//		client.WithConnPool(codec.ConnPool(connpool.NewLongPool("helloworld", connpool.IdleConfig{
+//			MaxIdlePerAddress: 10,
+//			MaxIdleGlobal:     100,
+//			MaxIdleTimeout:    time.Minute,
+//		}))),
+//	)
+func (m *DubboCodec) ConnPool(inner remote.ConnPool) remote.ConnPool {
+	p := newHeartbeatPool(inner, m.opt.HeartbeatInterval, m.opt.HeartbeatThreshold, m.readonly)
+	p.maxPayload = m.opt.MaxPayload
+	return p
+}
+
+var _ remote.LongConnPool = (*heartbeatPool)(nil)
+
+// heartbeatPool sends dubbo heartbeat requests on connections that are idle in the inner pool,
+// and closes connections that miss threshold heartbeat replies. Heartbeat is disabled if interval is not positive.
+// Events buffered on idle connections are processed every idleCheckInterval and when connections are put back.
+type heartbeatPool struct {
+	inner     remote.ConnPool
+	interval  time.Duration
+	threshold int
+	timeout   time.Duration
+	providers *readonlyProviders
+	// maxPayload limits the body of the packages received on the idle connections, see WithMaxPayload.
+	maxPayload int
+
+	mu sync.Mutex
+	// key: address string of the provider
+	conns map[string]map[net.Conn]*heartbeatConn
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+func newHeartbeatPool(inner remote.ConnPool, interval time.Duration, threshold int, providers *readonlyProviders) *heartbeatPool {
+	timeout := interval
+	if timeout > maxHeartbeatTimeout {
+		timeout = maxHeartbeatTimeout
+	}
+	p := &heartbeatPool{
+		inner:     inner,
+		interval:  interval,
+		threshold: threshold,
+		timeout:   timeout,
+		providers: providers,
+		conns:     make(map[string]map[net.Conn]*heartbeatConn),
+		closeCh:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// heartbeatConn records the state of a connection managed by heartbeatPool.
+type heartbeatConn struct {
+	mu    sync.Mutex
+	inUse bool
+	// beating is closed when the heartbeat or event processing in progress finishes, nil if there is none in progress.
+	beating  chan struct{}
+	lastUsed time.Time
+	missed   int
+}
+
+// acquire marks the connection in use by rpc call, it waits for the heartbeat in progress.
+func (hc *heartbeatConn) acquire() {
+	for {
+		hc.mu.Lock()
+		if hc.beating == nil {
+			hc.inUse = true
+			hc.mu.Unlock()
+			return
+		}
+		beating := hc.beating
+		hc.mu.Unlock()
+		<-beating
+	}
+}
+
+func (hc *heartbeatConn) release() {
+	hc.mu.Lock()
+	hc.inUse = false
+	hc.lastUsed = time.Now()
+	hc.mu.Unlock()
+}
+
+// tryBeat marks the heartbeat in progress if the connection has been idle for interval.
+func (hc *heartbeatConn) tryBeat(interval time.Duration) bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.inUse || hc.beating != nil || time.Since(hc.lastUsed) < interval {
+		return false
+	}
+	hc.beating = make(chan struct{})
+	return true
+}
+
+// finishBeat records the heartbeat result and returns the number of continuously missed replies.
+func (hc *heartbeatConn) finishBeat(replied bool) int {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if replied {
+		hc.missed = 0
+	} else {
+		hc.missed++
+	}
+	hc.lastUsed = time.Now()
+	close(hc.beating)
+	hc.beating = nil
+	return hc.missed
+}
+
+// tryIdle marks the event processing in progress if the connection is idle,
+// rpc calls getting the connection would wait for it like the heartbeat.
+func (hc *heartbeatConn) tryIdle() bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.inUse || hc.beating != nil {
+		return false
+	}
+	hc.beating = make(chan struct{})
+	return true
+}
+
+func (hc *heartbeatConn) finishIdle() {
+	hc.mu.Lock()
+	close(hc.beating)
+	hc.beating = nil
+	hc.mu.Unlock()
+}
+
+func (p *heartbeatPool) Get(ctx context.Context, network, address string, opt remote.ConnOption) (net.Conn, error) {
+	conn, err := p.inner.Get(ctx, network, address, opt)
+	if err != nil {
+		return nil, err
+	}
+	addr := connAddr(conn)
+	p.mu.Lock()
+	conns, ok := p.conns[addr]
+	if !ok {
+		conns = make(map[net.Conn]*heartbeatConn)
+		p.conns[addr] = conns
+	}
+	hc, ok := conns[conn]
+	if !ok {
+		hc = new(heartbeatConn)
+		conns[conn] = hc
+	}
+	p.mu.Unlock()
+	hc.acquire()
+	return conn, nil
+}
+
+func (p *heartbeatPool) Put(conn net.Conn) error {
+	p.mu.Lock()
+	hc, ok := p.conns[connAddr(conn)][conn]
+	p.mu.Unlock()
+	if ok {
+		hc.release()
+		// events received after the response of the rpc call
+		if hc.tryIdle() {
+			p.processIdleEvents(conn, hc)
+		}
+	}
+	return p.inner.Put(conn)
+}
+
+func (p *heartbeatPool) Discard(conn net.Conn) error {
+	p.mu.Lock()
+	p.deleteLocked(conn)
+	p.mu.Unlock()
+	return p.inner.Discard(conn)
+}
+
+func (p *heartbeatPool) deleteLocked(conn net.Conn) {
+	addr := connAddr(conn)
+	delete(p.conns[addr], conn)
+	if len(p.conns[addr]) == 0 {
+		delete(p.conns, addr)
+	}
+}
+
+func (p *heartbeatPool) Clean(network, address string) {
+	if lp, ok := p.inner.(remote.LongConnPool); ok {
+		lp.Clean(network, address)
+	}
+}
+
+func (p *heartbeatPool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closeCh)
+	})
+	return p.inner.Close()
+}
+
+func (p *heartbeatPool) run() {
+	interval := idleCheckInterval
+	if p.interval > 0 && p.interval < interval {
+		interval = p.interval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closeCh:
+			return
+		case <-ticker.C:
+			p.checkIdleConns()
+		}
+	}
+}
+
+// checkIdleConns sends heartbeat requests on the connections that have been idle for interval,
+// and processes the events buffered on the other idle connections.
+func (p *heartbeatPool) checkIdleConns() {
+	idle := make(map[net.Conn]*heartbeatConn)
+	p.mu.Lock()
+	for _, conns := range p.conns {
+		for conn, hc := range conns {
+			// connections closed by the inner pool or the peer
+			if !isActive(conn) {
+				p.deleteLocked(conn)
+				continue
+			}
+			// events are also processed when waiting for the heartbeat reply
+			if p.interval > 0 && hc.tryBeat(p.interval) {
+				go p.beat(conn, hc)
+				continue
+			}
+			if hc.tryIdle() {
+				idle[conn] = hc
+			}
+		}
+	}
+	p.mu.Unlock()
+	for conn, hc := range idle {
+		p.processIdleEvents(conn, hc)
+	}
+}
+
+func (p *heartbeatPool) beat(conn net.Conn, hc *heartbeatConn) {
+	err := p.ping(rawConn(conn))
+	var payloadErr *ExceedPayloadLimitError
+	if errors.As(err, &payloadErr) {
+		// the rest of the package is left in the connection, it could not be used any more
+		hc.finishBeat(false)
+		klog.Warnf("KITEX: dubbo connection to %s received too large package, close it, err: %s", conn.RemoteAddr(), err)
+		p.mu.Lock()
+		p.deleteLocked(conn)
+		p.mu.Unlock()
+		conn.Close()
+		return
+	}
+	if missed := hc.finishBeat(err == nil); missed >= p.threshold {
+		klog.Warnf("KITEX: dubbo connection to %s missed %d heartbeat replies, close it, last err: %s",
+			conn.RemoteAddr(), missed, err)
+		p.mu.Lock()
+		p.deleteLocked(conn)
+		p.mu.Unlock()
+		// the inner pool would drop the inactive connection when getting it
+		conn.Close()
+	}
+}
+
+// processIdleEvents processes the packages which have been received on the idle connection marked by tryIdle.
+// Only netpoll connections are supported, since the poller buffers their input data and it could be checked without blocking.
+func (p *heartbeatPool) processIdleEvents(conn net.Conn, hc *heartbeatConn) {
+	err := p.processBufferedPackages(rawConn(conn))
+	hc.finishIdle()
+	if err != nil {
+		klog.Warnf("KITEX: dubbo process events received on idle connection to %s failed, close it, err: %s",
+			conn.RemoteAddr(), err)
+		p.mu.Lock()
+		p.deleteLocked(conn)
+		p.mu.Unlock()
+		conn.Close()
+	}
+}
+
+// bufferedConn is implemented by netpoll connections.
+type bufferedConn interface {
+	Reader() netpoll.Reader
+}
+
+// processBufferedPackages processes the entire packages buffered on conn without blocking.
+func (p *heartbeatPool) processBufferedPackages(conn net.Conn) error {
+	bc, ok := conn.(bufferedConn)
+	if !ok {
+		return nil
+	}
+	reader := bc.Reader()
+	for reader.Len() >= dubbo_spec.HEADER_SIZE {
+		buf, err := reader.Peek(dubbo_spec.HEADER_SIZE)
+		if err != nil {
+			return err
+		}
+		header := new(dubbo_spec.DubboHeader)
+		if err := header.DecodeFromByteSlice(buf); err != nil {
+			return err
+		}
+		if err := checkPayload(int(header.DataLength), p.maxPayload); err != nil {
+			return err
+		}
+		// the package has not been received entirely
+		if reader.Len() < dubbo_spec.HEADER_SIZE+int(header.DataLength) {
+			return nil
+		}
+		header, body, err := readPackage(conn, p.maxPayload)
+		if err != nil {
+			return err
+		}
+		// responses are stale ones of the previous rpc calls
+		if !header.IsEvent || !header.IsRequest {
+			continue
+		}
+		if err := p.processEvent(conn, header, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ping sends heartbeat request on conn and waits for the reply.
+// Events sent by provider are processed when waiting.
+func (p *heartbeatPool) ping(conn net.Conn) error {
+	pkg, requestID, err := buildEventRequest(nil, true)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(pkg); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(p.timeout)
+	defer setReadTimeout(conn, 0)
+	for {
+		timeout := time.Until(deadline)
+		if timeout <= 0 {
+			return errHeartbeatTimeout
+		}
+		if err := setReadTimeout(conn, timeout); err != nil {
+			return err
+		}
+		header, body, err := readPackage(conn, p.maxPayload)
+		if err != nil {
+			return err
+		}
+		if !header.IsEvent {
+			// stale response of the previous rpc call
+			continue
+		}
+		if !header.IsRequest {
+			if header.RequestID == requestID {
+				return nil
+			}
+			continue
+		}
+		if err := p.processEvent(conn, header, body); err != nil {
+			return err
+		}
+	}
+}
+
+// processEvent processes the event request sent by provider, heartbeat requests are replied.
+func (p *heartbeatPool) processEvent(conn net.Conn, header *dubbo_spec.DubboHeader, body []byte) error {
+	serialization, ok := dubbo_spec.GetSerialization(header.SerializationID)
+	if !ok {
+		return fmt.Errorf("unsupported SerializationID: %d", header.SerializationID)
+	}
+	event, err := dubbo_spec.DecodeEvent(serialization.NewDecoder(body))
+	if err != nil {
+		return err
+	}
+	// heartbeat request sent by provider
+	if event == nil && !header.IsOneWay {
+		resp, err := buildHeartbeatResponse(header.RequestID)
+		if err != nil {
+			return err
+		}
+		_, err = conn.Write(resp)
+		return err
+	}
+	p.providers.handleEvent(conn.RemoteAddr(), event)
+	return nil
+}
+
+// readPackage reads an entire dubbo package from conn, ExceedPayloadLimitError is returned before reading the body
+// if it is larger than maxPayload.
+func readPackage(conn net.Conn, maxPayload int) (*dubbo_spec.DubboHeader, []byte, error) {
+	buf := make([]byte, dubbo_spec.HEADER_SIZE)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, nil, err
+	}
+	header := new(dubbo_spec.DubboHeader)
+	if err := header.DecodeFromByteSlice(buf); err != nil {
+		return nil, nil, err
+	}
+	if err := checkPayload(int(header.DataLength), maxPayload); err != nil {
+		return nil, nil, err
+	}
+	body := make([]byte, header.DataLength)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, nil, err
+	}
+	return header, body, nil
+}
+
+func connAddr(conn net.Conn) string {
+	if addr := conn.RemoteAddr(); addr != nil {
+		return addr.String()
+	}
+	return ""
+}
+
+// rawConn returns the real connection wrapped by the connection pool.
+func rawConn(conn net.Conn) net.Conn {
+	if raw, ok := conn.(remote.RawConn); ok {
+		return raw.RawConn()
+	}
+	return conn
+}
+
+func isActive(conn net.Conn) bool {
+	if active, ok := rawConn(conn).(interface{ IsActive() bool }); ok {
+		return active.IsActive()
+	}
+	return true
+}
+
+// setReadTimeout sets read timeout of conn, netpoll connection does not support SetReadDeadline.
+// timeout 0 means no timeout.
+func setReadTimeout(conn net.Conn, timeout time.Duration) error {
+	if c, ok := conn.(interface{ SetReadTimeout(time.Duration) error }); ok {
+		return c.SetReadTimeout(timeout)
+	}
+	if timeout == 0 {
+		return conn.SetReadDeadline(time.Time{})
+	}
+	return conn.SetReadDeadline(time.Now().Add(timeout))
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/netpoll"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatPoolPing(t *testing.T) {
+	p := &heartbeatPool{timeout: 100 * time.Millisecond, providers: newReadonlyProviders()}
+
+	t.Run("reply", func(t *testing.T) {
+		cli, svr := net.Pipe()
+		defer cli.Close()
+		defer svr.Close()
+		go func() {
+			header, _, err := readPackage(svr, 0)
+			if err != nil {
+				return
+			}
+			// provider sends READONLY_EVENT before replying the heartbeat
+			event, _, _ := buildEventRequest(dubbo_spec.READONLY_EVENT, false)
+			svr.Write(event)
+			resp, _ := buildHeartbeatResponse(header.RequestID)
+			svr.Write(resp)
+		}()
+		assert.Nil(t, p.ping(cli))
+		assert.True(t, p.providers.isReadonly(cli.RemoteAddr()))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		cli, svr := net.Pipe()
+		defer cli.Close()
+		defer svr.Close()
+		go readPackage(svr, 0)
+		assert.NotNil(t, p.ping(cli))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		p := &heartbeatPool{timeout: 100 * time.Millisecond, providers: newReadonlyProviders(), maxPayload: 64}
+		cli, svr := net.Pipe()
+		defer svr.Close()
+		go func() {
+			header, _, err := readPackage(svr, 0)
+			if err != nil {
+				return
+			}
+			resp, _ := buildHeartbeatResponse(header.RequestID)
+			binary.BigEndian.PutUint32(resp[12:dubbo_spec.HEADER_SIZE], 1<<30)
+			svr.Write(resp)
+		}()
+		hc := new(heartbeatConn)
+		assert.True(t, hc.tryBeat(0))
+		// the body is not read, and the connection is closed at once
+		p.beat(cli, hc)
+		_, err := cli.Write([]byte{0})
+		assert.Equal(t, io.ErrClosedPipe, err)
+	})
+}
+
+func TestHeartbeatConn(t *testing.T) {
+	hc := new(heartbeatConn)
+	hc.acquire()
+	assert.False(t, hc.tryBeat(0))
+	hc.release()
+	assert.False(t, hc.tryBeat(time.Hour))
+	assert.True(t, hc.tryBeat(0))
+
+	acquired := make(chan struct{})
+	go func() {
+		hc.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire should wait for the heartbeat in progress")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.Equal(t, 1, hc.finishBeat(false))
+	<-acquired
+	hc.release()
+	assert.True(t, hc.tryBeat(0))
+	assert.Equal(t, 0, hc.finishBeat(true))
+}
+
+func TestHeartbeatPoolMaxPayload(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"), WithMaxPayload(64))
+	conn := &bufferedTestConn{addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 20001}, input: netpoll.NewLinkBuffer()}
+	pool := codec.ConnPool(&testConnPool{conn: conn}).(*heartbeatPool)
+	defer pool.Close()
+	c, err := pool.Get(context.Background(), "tcp", conn.addr.String(), remote.ConnOption{})
+	assert.Nil(t, err)
+	assert.Nil(t, pool.Put(c))
+
+	// only the header of the oversized event is received, which should not be waited for
+	event, _, _ := buildEventRequest(dubbo_spec.READONLY_EVENT, false)
+	binary.BigEndian.PutUint32(event[12:dubbo_spec.HEADER_SIZE], 1<<30)
+	conn.input.WriteBinary(event[:dubbo_spec.HEADER_SIZE])
+	assert.Nil(t, conn.input.Flush())
+	pool.checkIdleConns()
+
+	assert.True(t, conn.closed)
+	assert.Empty(t, pool.conns)
+	assert.False(t, codec.readonly.isReadonly(conn.addr))
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+)
+
+// GENERIC_CLASS_KEY is the key of the java class name in the generic form of POJOs.
+const GENERIC_CLASS_KEY = "class"
+
+var _typeOfTime = reflect.TypeOf(time.Time{})
+
+// enumValuer is implemented by the enums generated by kitex.
+type enumValuer interface {
+	EnumValue(s string) hessian.JavaEnum
+}
+
+var _typeOfEnumValuer = reflect.TypeOf((*enumValuer)(nil)).Elem()
+
+// Realize converts v in generic form (primitives, slices and maps, e.g. the arguments of GenericService#$invoke)
+// to the value of typ. POJOs in map form are converted to structs by matching the keys with the hessian field names,
+// enums in string form are converted by their EnumValue method.
+func Realize(v interface{}, typ reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(typ), nil
+	}
+	val := reflect.ValueOf(v)
+	if val.Type().AssignableTo(typ) {
+		res := reflect.New(typ).Elem()
+		res.Set(val)
+		return res, nil
+	}
+
+	if s, ok := v.(string); ok && typ.Implements(_typeOfEnumValuer) {
+		enumVal := reflect.Zero(typ).Interface().(enumValuer).EnumValue(s)
+		if enumVal == hessian.InvalidJavaEnum {
+			return reflect.Value{}, fmt.Errorf("invalid enum value %s for %s", s, typ)
+		}
+		return reflect.ValueOf(enumVal).Convert(typ), nil
+	}
+
+	// java.util.Date is serialized as milliseconds by JSON serializations
+	if typ == _typeOfTime && isNumberKind(val.Kind()) {
+		ms := val.Convert(_typeOfInt64Ptr.Elem()).Int()
+		return reflect.ValueOf(time.Unix(0, ms*int64(time.Millisecond))), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := Realize(v, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res := reflect.New(typ.Elem())
+		res.Elem().Set(elem)
+		return res, nil
+	case reflect.Struct:
+		if val.Kind() != reflect.Map {
+			break
+		}
+		return realizeStruct(val, typ)
+	case reflect.Slice:
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+			break
+		}
+		res := reflect.MakeSlice(typ, val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			elem, err := Realize(val.Index(i).Interface(), typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res.Index(i).Set(elem)
+		}
+		return res, nil
+	case reflect.Map:
+		if val.Kind() != reflect.Map {
+			break
+		}
+		res := reflect.MakeMapWithSize(typ, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			key, err := Realize(iter.Key().Interface(), typ.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elem, err := Realize(iter.Value().Interface(), typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			res.SetMapIndex(key, elem)
+		}
+		return res, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// numbers in generic form may be of different types, e.g. float64 decoded from json
+		if isNumberKind(val.Kind()) {
+			return val.Convert(typ), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("can not realize %T to %s", v, typ)
+}
+
+func realizeStruct(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	fields := make(map[string]reflect.Value, val.Len())
+	iter := val.MapRange()
+	for iter.Next() {
+		key := iter.Key()
+		if key.Kind() == reflect.Interface {
+			key = key.Elem()
+		}
+		if key.Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("can not realize map with %s key to %s", key.Type(), typ)
+		}
+		fields[key.String()] = iter.Value()
+	}
+
+	res := reflect.New(typ).Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// unexported field
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldVal, ok := fields[hessianFieldName(field)]
+		if !ok {
+			continue
+		}
+		realized, err := Realize(fieldVal.Interface(), field.Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("realize field %s of %s failed: %s", field.Name, typ, err)
+		}
+		res.Field(i).Set(realized)
+	}
+	return res, nil
+}
+
+// Generalize converts v to generic form in the same way as PojoUtils#generalize of dubbo-java.
+// POJOs are converted to map[string]interface{} with the java class name stored in GENERIC_CLASS_KEY,
+// enums are converted to their names, slices and maps are converted recursively.
+func Generalize(v interface{}) interface{} {
+	return generalize(v, true)
+}
+
+// Simplify converts v to generic form in the same way as Generalize except that java class names are omitted.
+// It is used by serializations carrying no type information, e.g. JSON.
+func Simplify(v interface{}) interface{} {
+	return generalize(v, false)
+}
+
+func generalize(v interface{}, withClass bool) interface{} {
+	switch typ := v.(type) {
+	case nil:
+		return nil
+	case hessian.POJOEnum:
+		return typ.String()
+	case time.Time, []byte:
+		return v
+	}
+
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		// JavaClassName of generated POJOs is implemented by pointer receiver
+		if pojo, ok := v.(hessian.POJO); ok && val.Elem().Kind() == reflect.Struct {
+			return generalizeStruct(val.Elem(), pojo.JavaClassName(), withClass)
+		}
+		return generalize(val.Elem().Interface(), withClass)
+	case reflect.Struct:
+		if val.Type() == _typeOfTime {
+			return v
+		}
+		var className string
+		if pojo, ok := v.(hessian.POJO); ok {
+			className = pojo.JavaClassName()
+		}
+		return generalizeStruct(val, className, withClass)
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil
+		}
+		res := make([]interface{}, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			res[i] = generalize(val.Index(i).Interface(), withClass)
+		}
+		return res
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		res := make(map[interface{}]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			res[generalize(iter.Key().Interface(), withClass)] = generalize(iter.Value().Interface(), withClass)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+func generalizeStruct(val reflect.Value, className string, withClass bool) map[string]interface{} {
+	typ := val.Type()
+	res := make(map[string]interface{}, typ.NumField()+1)
+	if withClass && className != "" {
+		res[GENERIC_CLASS_KEY] = className
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		res[hessianFieldName(field)] = generalize(val.Field(i).Interface(), withClass)
+	}
+	return res
+}
+
+// hessianFieldName returns the java field name of the struct field in the same way as dubbo-go-hessian2,
+// which is specified by the hessian tag or the field name with the first letter lowercased.
+func hessianFieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("hessian"); name != "" && name != "-" {
+		return name
+	}
+	r, size := utf8.DecodeRuneInString(field.Name)
+	return string(unicode.ToLower(r)) + field.Name[size:]
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"reflect"
+	"testing"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+type genericTestEnum int64
+
+const (
+	genericTestEnum_ONE genericTestEnum = 1
+	genericTestEnum_TWO genericTestEnum = 2
+)
+
+func (e genericTestEnum) String() string {
+	switch e {
+	case genericTestEnum_ONE:
+		return "ONE"
+	case genericTestEnum_TWO:
+		return "TWO"
+	}
+	return "<UNSET>"
+}
+
+func (genericTestEnum) JavaClassName() string {
+	return "org.cloudwego.kitex.test.TestEnum"
+}
+
+func (genericTestEnum) EnumValue(s string) hessian.JavaEnum {
+	switch s {
+	case "ONE":
+		return hessian.JavaEnum(genericTestEnum_ONE)
+	case "TWO":
+		return hessian.JavaEnum(genericTestEnum_TWO)
+	}
+	return hessian.InvalidJavaEnum
+}
+
+type genericTestInner struct {
+	Name string
+}
+
+func (*genericTestInner) JavaClassName() string {
+	return "org.cloudwego.kitex.test.Inner"
+}
+
+type genericTestPOJO struct {
+	Id      int32
+	Score   *float64
+	Tags    []string
+	Inner   *genericTestInner
+	Inners  []*genericTestInner
+	Extra   map[string]int64
+	Enum    genericTestEnum
+	Renamed string `hessian:"alias"`
+}
+
+func (*genericTestPOJO) JavaClassName() string {
+	return "org.cloudwego.kitex.test.POJO"
+}
+
+func TestRealize(t *testing.T) {
+	score := 1.5
+	expected := &genericTestPOJO{
+		Id:      1,
+		Score:   &score,
+		Tags:    []string{"a", "b"},
+		Inner:   &genericTestInner{Name: "inner"},
+		Inners:  []*genericTestInner{{Name: "inner0"}},
+		Extra:   map[string]int64{"key": 2},
+		Enum:    genericTestEnum_TWO,
+		Renamed: "renamed",
+	}
+	// map-form arguments decoded by hessian2
+	hessianArg := map[interface{}]interface{}{
+		"class":  "org.cloudwego.kitex.test.POJO",
+		"id":     int32(1),
+		"score":  1.5,
+		"tags":   []interface{}{"a", "b"},
+		"inner":  map[interface{}]interface{}{"name": "inner"},
+		"inners": []interface{}{map[interface{}]interface{}{"name": "inner0"}},
+		"extra":  map[interface{}]interface{}{"key": int64(2)},
+		"enum":   "TWO",
+		"alias":  "renamed",
+	}
+	// map-form arguments decoded by encoding/json
+	jsonArg := map[string]interface{}{
+		"id":     float64(1),
+		"score":  1.5,
+		"tags":   []interface{}{"a", "b"},
+		"inner":  map[string]interface{}{"name": "inner"},
+		"inners": []interface{}{map[string]interface{}{"name": "inner0"}},
+		"extra":  map[string]interface{}{"key": float64(2)},
+		"enum":   "TWO",
+		"alias":  "renamed",
+	}
+
+	for _, arg := range []interface{}{hessianArg, jsonArg} {
+		res, err := Realize(arg, reflect.TypeOf(expected))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res.Interface())
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Realize("str", reflect.TypeOf(int32(0)))
+		assert.NotNil(t, err)
+		_, err = Realize("THREE", reflect.TypeOf(genericTestEnum_ONE))
+		assert.NotNil(t, err)
+		_, err = Realize(map[interface{}]interface{}{int32(1): "val"}, reflect.TypeOf(genericTestInner{}))
+		assert.NotNil(t, err)
+		// errors of elements are not dropped
+		assert.NotNil(t, ReflectResponse([]interface{}{"THREE"}, new([]genericTestEnum)))
+		assert.NotNil(t, ReflectResponse(map[interface{}]interface{}{"key": "THREE"}, new(map[string]genericTestEnum)))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		res, err := Realize(nil, reflect.TypeOf(expected))
+		assert.Nil(t, err)
+		assert.True(t, res.IsNil())
+	})
+}
+
+func TestGeneralize(t *testing.T) {
+	score := 1.5
+	pojo := &genericTestPOJO{
+		Id:      1,
+		Score:   &score,
+		Tags:    []string{"a"},
+		Inner:   &genericTestInner{Name: "inner"},
+		Extra:   map[string]int64{"key": 2},
+		Enum:    genericTestEnum_ONE,
+		Renamed: "renamed",
+	}
+	expected := map[string]interface{}{
+		"class":  "org.cloudwego.kitex.test.POJO",
+		"id":     int32(1),
+		"score":  1.5,
+		"tags":   []interface{}{"a"},
+		"inner":  map[string]interface{}{"class": "org.cloudwego.kitex.test.Inner", "name": "inner"},
+		"inners": nil,
+		"extra":  map[interface{}]interface{}{"key": int64(2)},
+		"enum":   "ONE",
+		"alias":  "renamed",
+	}
+	assert.Equal(t, expected, Generalize(pojo))
+	assert.Nil(t, Generalize((*genericTestPOJO)(nil)))
+	assert.Equal(t, "str", Generalize("str"))
+}
@@ -31,6 +31,7 @@ import (
 	"time"
 
 	hessian "github.com/apache/dubbo-go-hessian2"
+	"github.com/apache/dubbo-go-hessian2/java8_time"
 )
 
 // MethodCache maintains a cache from method parameter types (reflect.Type) and method annotations to the type strings used by Hessian2.
@@ -127,6 +128,16 @@ func GetParamsTypeList(params []*Parameter) (string, error) {
 	return types, nil
 }
 
+// GetTypesByJavaTypes returns the Types string for the given java types, e.g. "int", "java.lang.String",
+// which are specified by the ParameterTypes of GenericService#$invoke.
+func GetTypesByJavaTypes(javaTypes []string) (string, error) {
+	params := make([]*Parameter, len(javaTypes))
+	for i, javaType := range javaTypes {
+		params[i] = NewParameter(nil, javaType)
+	}
+	return GetParamsTypeList(params)
+}
+
 // Parameter is used to store information about parameters.
 // value stores the actual value of the parameter, and typeAnno records the type annotation added by IDL to this parameter.
 type Parameter struct {
@@ -231,6 +242,38 @@ func (p *Parameter) getTypeByAnno() string {
 		return "java.lang.Object"
 	case "Object[]":
 		return "[Ljava.lang.Object;"
+	case "BigDecimal":
+		return "java.math.BigDecimal"
+	case "BigDecimal[]":
+		return "[Ljava.math.BigDecimal;"
+	case "BigInteger":
+		return "java.math.BigInteger"
+	case "BigInteger[]":
+		return "[Ljava.math.BigInteger;"
+	case "LocalDate":
+		return "java.time.LocalDate"
+	case "LocalDate[]":
+		return "[Ljava.time.LocalDate;"
+	case "LocalTime":
+		return "java.time.LocalTime"
+	case "LocalTime[]":
+		return "[Ljava.time.LocalTime;"
+	case "LocalDateTime":
+		return "java.time.LocalDateTime"
+	case "LocalDateTime[]":
+		return "[Ljava.time.LocalDateTime;"
+	case "Instant":
+		return "java.time.Instant"
+	case "Instant[]":
+		return "[Ljava.time.Instant;"
+	case "Duration":
+		return "java.time.Duration"
+	case "Duration[]":
+		return "[Ljava.time.Duration;"
+	case "ZonedDateTime":
+		return "java.time.ZonedDateTime"
+	case "ZonedDateTime[]":
+		return "[Ljava.time.ZonedDateTime;"
 	default:
 		if strings.HasSuffix(p.typeAnno, "[]") {
 			return "[L" + p.typeAnno[:len(p.typeAnno)-2] + ";"
@@ -268,6 +311,19 @@ func (p *Parameter) getTypeByValue() string {
 		return "java.util.Date"
 	case []time.Time:
 		return "[Ljava.util.Date"
+	// the JavaClassNames of java8_time types are the names of hessian-lite handles rather than java.time classes
+	case java8_time.LocalDate, *java8_time.LocalDate:
+		return "java.time.LocalDate"
+	case java8_time.LocalTime, *java8_time.LocalTime:
+		return "java.time.LocalTime"
+	case java8_time.LocalDateTime, *java8_time.LocalDateTime:
+		return "java.time.LocalDateTime"
+	case java8_time.Instant, *java8_time.Instant:
+		return "java.time.Instant"
+	case java8_time.Duration, *java8_time.Duration:
+		return "java.time.Duration"
+	case java8_time.ZonedDateTime, *java8_time.ZonedDateTime:
+		return "java.time.ZonedDateTime"
 	case string:
 		return "java.lang.String"
 	case []hessian.Object:
@@ -281,10 +337,22 @@ func (p *Parameter) getTypeByValue() string {
 	default:
 		reflectTyp := reflect.TypeOf(typ)
 		if reflect.Ptr == reflectTyp.Kind() {
-			reflectTyp = reflect.TypeOf(reflect.ValueOf(typ).Elem())
+			reflectTyp = reflectTyp.Elem()
+			switch reflectTyp.Kind() {
+			case reflect.Struct, reflect.Interface:
+			default:
+				// pointers to basic types, e.g. optional fields, are typed by the zero value regardless of nil.
+				return NewParameter(reflect.Zero(reflectTyp).Interface(), "").getTypeByValue()
+			}
 		}
 		switch reflectTyp.Kind() {
+		case reflect.Interface:
+			return "java.lang.Object"
 		case reflect.Struct:
+			if val := reflect.ValueOf(typ); val.Kind() == reflect.Ptr && val.IsNil() {
+				// avoid calling value methods with nil pointers
+				typ = reflect.New(reflectTyp).Interface()
+			}
 			hessianParam, ok := typ.(hessian.Param)
 			if ok {
 				return hessianParam.JavaParamName()
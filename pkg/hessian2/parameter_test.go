@@ -208,3 +208,24 @@ func TestTypesCache_getTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestParameter_getTypeByValue(t *testing.T) {
+	tests := []struct {
+		desc     string
+		value    interface{}
+		expected string
+	}{
+		{desc: "nil struct pointer", value: (*testInternalStruct)(nil), expected: "java.lang.Object"},
+		{desc: "struct pointer", value: &testInternalStruct{Field: 1}, expected: "java.lang.Object"},
+		{desc: "nil interface pointer", value: (*interface{})(nil), expected: "java.lang.Object"},
+		{desc: "nil string pointer", value: (*string)(nil), expected: "java.lang.String"},
+		{desc: "int32 pointer", value: new(int32), expected: "java.lang.Integer"},
+		{desc: "nil slice", value: []int32(nil), expected: "java.util.List"},
+		{desc: "nil map", value: map[string]int32(nil), expected: "java.util.Map"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewParameter(test.value, "").getTypeByValue())
+		})
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+
+	gost_big "github.com/dubbogo/gost/math/big"
 )
 
 // _Rune is an alias for rune, so that to get the correct runtime type of rune.
@@ -108,29 +110,29 @@ func ReflectResponse(in, out interface{}) error {
 
 	outType := outValue.Type().String()
 	if outType == "interface {}" || outType == "*interface {}" {
-		setValue(outValue, inValue)
-		return nil
+		return setValue(outValue, inValue)
 	}
 
 	switch inValue.Type().Kind() {
 	case reflect.Slice, reflect.Array:
 		return copySlice(inValue, outValue)
 	case reflect.Map:
+		if ok, err := realizeGeneric(outValue.Elem(), inValue); ok || err != nil {
+			return err
+		}
 		return copyMap(inValue, outValue)
 	default:
-		setValue(outValue, inValue)
+		return setValue(outValue, inValue)
 	}
-
-	return nil
 }
 
 // setValue set the value to dest.
 // It will auto check the Ptr pack level and unpack/pack to the right level.
-// It makes sure success to set value
-func setValue(dest, v reflect.Value) {
+// It returns the error of realizing v in generic form.
+func setValue(dest, v reflect.Value) error {
 	// zero value not need to set
 	if !v.IsValid() {
-		return
+		return nil
 	}
 
 	vType := v.Type()
@@ -145,14 +147,14 @@ func setValue(dest, v reflect.Value) {
 	// for most cases, the types are the same and can set the value directly.
 	if dest.CanSet() && destType == vType {
 		dest.Set(v)
-		return
+		return nil
 	}
 
 	// check whether the v is a ref holder
 	if vType == _refHolderPtrType {
 		h := v.Interface().(*_refHolder)
 		h.add(dest)
-		return
+		return nil
 	}
 
 	vRawType, vPtrDepth := unpackType(vType)
@@ -178,32 +180,70 @@ func setValue(dest, v reflect.Value) {
 
 		dest.Set(v)
 
-		return
+		return nil
 	}
 
 	if vRawType.String() == "interface {}" {
 		v = v.Elem()
 	}
+	// BigInteger could be decoded to uint64 if it is in range
+	if i, ok := v.Interface().(*gost_big.Integer); ok && destRawType.Kind() == reflect.Uint64 {
+		if !i.Value().IsUint64() {
+			return fmt.Errorf("java.math.BigInteger %s overflows %s", i.String(), destRawType)
+		}
+		uv := reflect.ValueOf(i.Value().Uint64()).Convert(destRawType)
+		for j := 0; j < destPtrDepth; j++ {
+			uv = packPtr(uv)
+		}
+		dest.Set(uv)
+		return nil
+	}
+	if ok, err := realizeGeneric(dest, v); ok || err != nil {
+		return err
+	}
 	switch destType.Kind() {
 	case reflect.Float32, reflect.Float64:
 		dest.SetFloat(v.Float())
-		return
+		return nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		dest.SetInt(v.Int())
-		return
+		return nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		// hessian only support 64-bit signed long integer.
 		dest.SetUint(uint64(v.Int()))
-		return
+		return nil
 	case reflect.Ptr:
 		setValueToPtrDest(dest, v)
-		return
+		return nil
 	case reflect.Bool:
 		dest.SetBool(v.Bool())
 	default:
 		// It's ok when the dest is an interface{}, while the v is a pointer.
 		dest.Set(v)
 	}
+	return nil
+}
+
+// realizeGeneric sets v in generic form to dest. Values decoded by serializations carrying no type information
+// are in generic form, e.g. POJOs decoded by JSON are maps and enums are strings.
+// It reports false if v is not the generic form of dest.
+func realizeGeneric(dest, v reflect.Value) (bool, error) {
+	destRawType := unpackPtrType(dest.Type())
+	switch {
+	case v.Kind() == reflect.Map && destRawType.Kind() == reflect.Struct:
+	case v.Kind() == reflect.String && destRawType.Implements(_typeOfEnumValuer):
+	case isNumberKind(v.Kind()) && destRawType == _typeOfTime:
+	case isNumberKind(v.Kind()) && v.Kind() != destRawType.Kind() &&
+		(destRawType.Kind() == reflect.Float32 || destRawType.Kind() == reflect.Float64):
+	default:
+		return false, nil
+	}
+	realized, err := Realize(v.Interface(), dest.Type())
+	if err != nil {
+		return false, err
+	}
+	dest.Set(realized)
+	return true, nil
 }
 
 // copySlice copy from inSlice to outSlice
@@ -225,7 +265,9 @@ func copySlice(inSlice, outSlice reflect.Value) error {
 	for i := 0; i < size; i++ {
 		inSliceValue := inSlice.Index(i)
 		outSliceValue := reflect.New(outSlice.Index(i).Type()).Elem()
-		setValue(outSliceValue, inSliceValue)
+		if err := setValue(outSliceValue, inSliceValue); err != nil {
+			return err
+		}
 		outSlice.Index(i).Set(outSliceValue)
 	}
 
@@ -245,7 +287,9 @@ func copyMap(inMapValue, outMapValue reflect.Value) error {
 	}
 
 	outMapType := unpackPtrType(outMapValue.Type())
-	setValue(outMapValue, reflect.MakeMap(outMapType))
+	if err := setValue(outMapValue, reflect.MakeMap(outMapType)); err != nil {
+		return err
+	}
 
 	outKeyType := outMapType.Key()
 
@@ -255,9 +299,13 @@ func copyMap(inMapValue, outMapValue reflect.Value) error {
 	for _, inKey := range inMapValue.MapKeys() {
 		inValue := inMapValue.MapIndex(inKey)
 		outKey := reflect.New(outKeyType).Elem()
-		setValue(outKey, inKey)
+		if err := setValue(outKey, inKey); err != nil {
+			return err
+		}
 		outValue := reflect.New(outValueType).Elem()
-		setValue(outValue, inValue)
+		if err := setValue(outValue, inValue); err != nil {
+			return err
+		}
 
 		outMapValue.SetMapIndex(outKey, outValue)
 	}
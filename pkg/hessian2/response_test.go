@@ -20,10 +20,16 @@
 package hessian2
 
 import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/apache/dubbo-go-hessian2/java8_time"
+	"github.com/kitex-contrib/codec-dubbo/java"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -441,3 +447,245 @@ func testReflectResponse(t *testing.T, src, dest interface{}, expectErr bool) {
 		return
 	}
 }
+
+func TestReflectResponseNullable(t *testing.T) {
+	boolean, byt, short, integer, long, double := true, int8(1), int16(2), int32(3), int64(4), 5.5
+	tests := []struct {
+		desc     string
+		values   []interface{}
+		newDest  func() interface{}
+		expected string
+	}{
+		{
+			desc:     "java.Boolean",
+			values:   []interface{}{(*java.Boolean)(nil), &boolean},
+			newDest:  func() interface{} { return new(*java.Boolean) },
+			expected: "java.lang.Boolean",
+		},
+		{
+			desc:     "java.Byte",
+			values:   []interface{}{(*java.Byte)(nil), &byt},
+			newDest:  func() interface{} { return new(*java.Byte) },
+			expected: "java.lang.Byte",
+		},
+		{
+			desc:     "java.Short",
+			values:   []interface{}{(*java.Short)(nil), &short},
+			newDest:  func() interface{} { return new(*java.Short) },
+			expected: "java.lang.Short",
+		},
+		{
+			desc:     "java.Integer",
+			values:   []interface{}{(*java.Integer)(nil), &integer},
+			newDest:  func() interface{} { return new(*java.Integer) },
+			expected: "java.lang.Integer",
+		},
+		{
+			desc:     "java.Long",
+			values:   []interface{}{(*java.Long)(nil), &long},
+			newDest:  func() interface{} { return new(*java.Long) },
+			expected: "java.lang.Long",
+		},
+		{
+			desc:     "java.Double",
+			values:   []interface{}{(*java.Double)(nil), &double},
+			newDest:  func() interface{} { return new(*java.Double) },
+			expected: "java.lang.Double",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			for _, value := range test.values {
+				assert.Equal(t, test.expected, NewParameter(value, "").getType())
+
+				encoder := NewEncoder()
+				assert.Nil(t, encoder.Encode(value))
+				if reflect.ValueOf(value).IsNil() {
+					// java null
+					assert.Equal(t, []byte{'N'}, encoder.Buffer())
+				}
+				decoded, err := NewDecoder(encoder.Buffer()).Decode()
+				assert.Nil(t, err)
+				dest := test.newDest()
+				assert.Nil(t, ReflectResponse(decoded, dest))
+				assert.Equal(t, value, reflect.ValueOf(dest).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestReflectResponseBigNumber(t *testing.T) {
+	t.Run("java.BigDecimal", func(t *testing.T) {
+		decimal := java.NewBigDecimal()
+		assert.Nil(t, decimal.FromString("12345678901234567890.0123456789"))
+		assert.Equal(t, "java.math.BigDecimal", NewParameter(decimal, "").getType())
+		assert.Equal(t, "java.math.BigDecimal", NewParameter((*java.BigDecimal)(nil), "").getType())
+
+		encoder := NewEncoder()
+		assert.Nil(t, encoder.Encode(decimal))
+		decoded, err := NewDecoder(encoder.Buffer()).Decode()
+		assert.Nil(t, err)
+		var dest *java.BigDecimal
+		assert.Nil(t, ReflectResponse(decoded, &dest))
+		assert.Equal(t, "12345678901234567890.0123456789", dest.String())
+	})
+
+	t.Run("java.BigInteger", func(t *testing.T) {
+		integer := java.NewBigInteger()
+		assert.Nil(t, integer.FromString("-123456789012345678901234567890"))
+		assert.Equal(t, "java.math.BigInteger", NewParameter(integer, "").getType())
+
+		encoder := NewEncoder()
+		assert.Nil(t, encoder.Encode(integer))
+		decoded, err := NewDecoder(encoder.Buffer()).Decode()
+		assert.Nil(t, err)
+		var dest *java.BigInteger
+		assert.Nil(t, ReflectResponse(decoded, &dest))
+		assert.Equal(t, "-123456789012345678901234567890", dest.String())
+	})
+
+	t.Run("uint64", func(t *testing.T) {
+		value := uint64(math.MaxUint64)
+		integer := java.NewBigIntegerFromUint64(value)
+		assert.Equal(t, "18446744073709551615", integer.String())
+		assert.Equal(t, "java.math.BigInteger", NewParameter(integer, "").getType())
+
+		encoder := NewEncoder()
+		assert.Nil(t, encoder.Encode(integer))
+		assert.Nil(t, encoder.Encode([]interface{}{integer}))
+		decoder := NewDecoder(encoder.Buffer())
+		decoded, err := decoder.Decode()
+		assert.Nil(t, err)
+		var dest uint64
+		assert.Nil(t, ReflectResponse(decoded, &dest))
+		assert.Equal(t, value, dest)
+		var ptrDest *uint64
+		assert.Nil(t, ReflectResponse(decoded, &ptrDest))
+		assert.Equal(t, value, *ptrDest)
+		decoded, err = decoder.Decode()
+		assert.Nil(t, err)
+		var sliceDest []uint64
+		assert.Nil(t, ReflectResponse(decoded, &sliceDest))
+		assert.Equal(t, []uint64{value}, sliceDest)
+
+		// BigIntegers out of the range of uint64 are not truncated
+		for _, s := range []string{"-1", "18446744073709551616"} {
+			integer := java.NewBigInteger()
+			assert.Nil(t, integer.FromString(s))
+			encoder := NewEncoder()
+			assert.Nil(t, encoder.Encode(integer))
+			decoded, err := NewDecoder(encoder.Buffer()).Decode()
+			assert.Nil(t, err)
+			var dest uint64
+			assert.NotNil(t, ReflectResponse(decoded, &dest))
+		}
+	})
+}
+
+func TestReflectResponseJavaTime(t *testing.T) {
+	date := java.LocalDate{Year: 2020, Month: 6, Day: 16}
+	clock := java.LocalTime{Hour: 6, Minute: 5, Second: 4, Nano: 3}
+	dateTime := java.LocalDateTime{Date: date, Time: clock}
+
+	// The expected bytes are assembled by hand from the hessian 2.0 grammar, and compared with the output of hessian-lite
+	// in testdata once it is generated. They follow the class names and field order of the java8 handles of hessian-lite,
+	// which replace java.time objects:
+	// 'C' class definitions are followed by the field names and 0x60+n starts an object of definition n.
+	// Small ints are written as 0x90+v, larger ints as 0xc8+(v>>8) or 0xd4+(v>>16) followed by the low bytes,
+	// and the long seconds as 0xf8+(v>>8) followed by the low byte, e.g. 2020 is "\xcf\xe4" and 100 seconds is "\xf8\x64".
+	const (
+		dateBytes     = "C07com.alibaba.com.caucho.hessian.io.java8.LocalDateHandle\x93\x04year\x05month\x03day"
+		timeBytes     = "C07com.alibaba.com.caucho.hessian.io.java8.LocalTimeHandle\x94\x04hour\x06minute\x06second\x04nano"
+		dateTimeBytes = "C0;com.alibaba.com.caucho.hessian.io.java8.LocalDateTimeHandle\x92\x04date\x04time"
+	)
+	tests := []struct {
+		desc     string
+		value    interface{}
+		javaType string
+		bytes    string
+		newDest  func() interface{}
+	}{
+		{
+			desc:     "java.LocalDate",
+			value:    &date,
+			javaType: "java.time.LocalDate",
+			bytes:    dateBytes + "\x60\xcf\xe4\x96\xa0",
+			newDest:  func() interface{} { return new(*java.LocalDate) },
+		},
+		{
+			desc:     "java.LocalTime",
+			value:    &clock,
+			javaType: "java.time.LocalTime",
+			bytes:    timeBytes + "\x60\x96\x95\x94\x93",
+			newDest:  func() interface{} { return new(*java.LocalTime) },
+		},
+		{
+			desc:     "java.LocalDateTime",
+			value:    &dateTime,
+			javaType: "java.time.LocalDateTime",
+			bytes:    dateTimeBytes + "\x60" + dateBytes + "\x61\xcf\xe4\x96\xa0" + timeBytes + "\x62\x96\x95\x94\x93",
+			newDest:  func() interface{} { return new(*java.LocalDateTime) },
+		},
+		{
+			desc:     "java.Instant",
+			value:    &java.Instant{Seconds: 100, Nanos: 10},
+			javaType: "java.time.Instant",
+			bytes:    "C05com.alibaba.com.caucho.hessian.io.java8.InstantHandle\x92\x07seconds\x05nanos\x60\xf8\x64\x9a",
+			newDest:  func() interface{} { return new(*java.Instant) },
+		},
+		{
+			desc:     "java.Duration",
+			value:    &java.Duration{Seconds: 30, Nanos: 10},
+			javaType: "java.time.Duration",
+			bytes:    "C06com.alibaba.com.caucho.hessian.io.java8.DurationHandle\x92\x07seconds\x05nanos\x60\xf8\x1e\x9a",
+			newDest:  func() interface{} { return new(*java.Duration) },
+		},
+		{
+			desc: "java.ZonedDateTime",
+			value: &java.ZonedDateTime{
+				DateTime: dateTime,
+				Offset:   java8_time.ZoneOffSet{Seconds: 8 * 3600},
+				ZoneId:   "Asia/Shanghai",
+			},
+			javaType: "java.time.ZonedDateTime",
+			bytes: "C0;com.alibaba.com.caucho.hessian.io.java8.ZonedDateTimeHandle\x93\x08dateTime\x06offset\x06zoneId\x60" +
+				dateTimeBytes + "\x61" + dateBytes + "\x62\xcf\xe4\x96\xa0" + timeBytes + "\x63\x96\x95\x94\x93" +
+				"C08com.alibaba.com.caucho.hessian.io.java8.ZoneOffsetHandle\x91\x07seconds\x64\xd4\x70\x80" +
+				"\x0dAsia/Shanghai",
+			newDest: func() interface{} { return new(*java.ZonedDateTime) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.javaType, NewParameter(test.value, "").getType())
+			assert.Equal(t, test.javaType, NewParameter(nil, test.desc[len("java."):]).getType())
+			assert.Equal(t, "[L"+test.javaType+";", NewParameter(nil, test.desc[len("java."):]+"[]").getType())
+			assert.Equal(t, test.javaType, NewParameter(reflect.ValueOf(test.value).Elem().Interface(), "").getType())
+			assert.Equal(t, test.javaType, NewParameter(reflect.Zero(reflect.TypeOf(test.value)).Interface(), "").getType())
+
+			encoder := NewEncoder()
+			assert.Nil(t, encoder.Encode(test.value))
+			assert.Equal(t, test.bytes, string(encoder.Buffer()))
+
+			decoded, err := NewDecoder([]byte(test.bytes)).Decode()
+			assert.Nil(t, err)
+			dest := test.newDest()
+			assert.Nil(t, ReflectResponse(decoded, dest))
+			assert.Equal(t, test.value, reflect.ValueOf(dest).Elem().Interface())
+
+			// the fixtures are written by hessian-lite with testdata/JavaTimeFixtures.java
+			fixture, err := ioutil.ReadFile(filepath.Join("testdata", test.desc[len("java."):]+".bin"))
+			if os.IsNotExist(err) {
+				t.Skipf("%s has not been generated by testdata/JavaTimeFixtures.java", test.desc[len("java."):]+".bin")
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.bytes, string(fixture))
+			decoded, err = NewDecoder(fixture).Decode()
+			assert.Nil(t, err)
+			dest = test.newDest()
+			assert.Nil(t, ReflectResponse(decoded, dest))
+			assert.Equal(t, test.value, reflect.ValueOf(dest).Elem().Interface())
+		})
+	}
+}
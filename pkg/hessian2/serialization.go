@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+const SerializationName = "hessian2"
+
+func init() {
+	dubbo_spec.RegisterSerialization(serialization{})
+}
+
+// serialization is the default serialization of dubbo.
+type serialization struct{}
+
+func (serialization) ID() uint8 {
+	return dubbo_spec.SERIALIZATION_ID_HESSIAN
+}
+
+func (serialization) Name() string {
+	return SerializationName
+}
+
+func (serialization) NewEncoder() iface.Encoder {
+	return NewEncoder()
+}
+
+func (serialization) NewDecoder(b []byte) iface.Decoder {
+	return NewDecoder(b)
+}
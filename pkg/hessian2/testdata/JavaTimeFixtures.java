@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import java.io.FileOutputStream;
+import java.io.IOException;
+import java.io.OutputStream;
+import java.time.Duration;
+import java.time.Instant;
+import java.time.LocalDate;
+import java.time.LocalDateTime;
+import java.time.LocalTime;
+import java.time.ZoneId;
+import java.time.ZonedDateTime;
+
+import com.alibaba.com.caucho.hessian.io.Hessian2Output;
+
+/**
+ * Writes the fixtures read by TestReflectResponseJavaTime with hessian-lite, which is the hessian2 serialization of
+ * dubbo-java. Each file holds a single object written by Hessian2Output. Run it in pkg/hessian2 with
+ * com.alibaba:hessian-lite:3.2.13 on the classpath:
+ *
+ *   java -cp "$CLASSPATH" testdata/JavaTimeFixtures.java
+ */
+public class JavaTimeFixtures {
+
+    public static void main(String[] args) throws IOException {
+        LocalDate date = LocalDate.of(2020, 6, 16);
+        LocalTime time = LocalTime.of(6, 5, 4, 3);
+        LocalDateTime dateTime = LocalDateTime.of(date, time);
+
+        write("LocalDate", date);
+        write("LocalTime", time);
+        write("LocalDateTime", dateTime);
+        write("Instant", Instant.ofEpochSecond(100, 10));
+        write("Duration", Duration.ofSeconds(30, 10));
+        write("ZonedDateTime", ZonedDateTime.of(dateTime, ZoneId.of("Asia/Shanghai")));
+    }
+
+    private static void write(String name, Object value) throws IOException {
+        try (OutputStream file = new FileOutputStream("testdata/" + name + ".bin")) {
+            Hessian2Output out = new Hessian2Output(file);
+            out.writeObject(value);
+            out.flush();
+        }
+    }
+}
@@ -32,3 +32,19 @@ type Encoder interface {
 type Decoder interface {
 	Decode() (interface{}, error)
 }
+
+// TypedDecoder is implemented by the Decoders of serializations carrying no type information in the encoded bytes,
+// e.g. protobuf. DecodeTo decodes the next value according to the type of the value pointed to by v.
+type TypedDecoder interface {
+	Decoder
+	DecodeTo(v interface{}) error
+}
+
+// Serialization creates Encoder and Decoder of a dubbo serialization,
+// which is identified by the SerializationID in dubbo header.
+type Serialization interface {
+	ID() uint8
+	Name() string
+	NewEncoder() Encoder
+	NewDecoder(b []byte) Decoder
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package json implements the JSON serializations of dubbo, which are fastjson and gson.
+// Each value is written as a line of JSON text, the same as FastJsonObjectOutput and GsonJsonObjectOutput of dubbo-java.
+package json
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+const (
+	FastjsonSerializationName = "fastjson"
+	GsonSerializationName     = "gson"
+)
+
+func init() {
+	dubbo_spec.RegisterSerialization(&serialization{id: dubbo_spec.SERIALIZATION_ID_FASTJSON, name: FastjsonSerializationName})
+	dubbo_spec.RegisterSerialization(&serialization{id: dubbo_spec.SERIALIZATION_ID_GSON, name: GsonSerializationName})
+}
+
+// serialization encodes and decodes values in the same way for fastjson and gson,
+// they are only distinguished by the SerializationID.
+type serialization struct {
+	id   uint8
+	name string
+}
+
+func (s *serialization) ID() uint8 {
+	return s.id
+}
+
+func (s *serialization) Name() string {
+	return s.name
+}
+
+func (s *serialization) NewEncoder() iface.Encoder {
+	return NewEncoder()
+}
+
+func (s *serialization) NewDecoder(b []byte) iface.Decoder {
+	return NewDecoder(b)
+}
+
+// Encoder writes values as lines of JSON text.
+// POJOs are written as objects whose keys are the hessian field names, enums are written as their names
+// and time.Time is written as milliseconds.
+type Encoder struct {
+	buf []byte
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (e *Encoder) Encode(v interface{}) error {
+	data, err := stdjson.Marshal(toJSONValue(hessian2.Simplify(v)))
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, data...)
+	e.buf = append(e.buf, '\n')
+	return nil
+}
+
+func (e *Encoder) Buffer() []byte {
+	return e.buf
+}
+
+// Decoder reads values from lines of JSON text.
+// Objects are decoded as map[interface{}]interface{} and integers are decoded as int32 or int64 like hessian2,
+// typed values are converted from them by hessian2.ReflectResponse.
+type Decoder struct {
+	dec *stdjson.Decoder
+}
+
+func NewDecoder(b []byte) *Decoder {
+	dec := stdjson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return &Decoder{dec: dec}
+}
+
+func (d *Decoder) Decode() (interface{}, error) {
+	var v interface{}
+	if err := d.dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSONValue(v)
+}
+
+// toJSONValue converts v in generic form to the value accepted by encoding/json.
+func toJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		return val.UnixNano() / int64(time.Millisecond)
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, elem := range val {
+			res[i] = toJSONValue(elem)
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			res[fmt.Sprint(k)] = toJSONValue(elem)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			res[k] = toJSONValue(elem)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// fromJSONValue converts v decoded by encoding/json to the generic form decoded by hessian2.
+func fromJSONValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case stdjson.Number:
+		if i, err := val.Int64(); err == nil {
+			if int64(int32(i)) == i {
+				return int32(i), nil
+			}
+			return i, nil
+		}
+		return val.Float64()
+	case []interface{}:
+		for i, elem := range val {
+			res, err := fromJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = res
+		}
+		return val, nil
+	case map[string]interface{}:
+		res := make(map[interface{}]interface{}, len(val))
+		for k, elem := range val {
+			realElem, err := fromJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = realElem
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"testing"
+	"time"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+type testColor int32
+
+const testColorRed testColor = 1
+
+func (testColor) JavaClassName() string {
+	return "org.cloudwego.kitex.Color"
+}
+
+func (c testColor) String() string {
+	if c == testColorRed {
+		return "RED"
+	}
+	return ""
+}
+
+func (testColor) EnumValue(s string) hessian.JavaEnum {
+	if s == "RED" {
+		return hessian.JavaEnum(testColorRed)
+	}
+	return hessian.InvalidJavaEnum
+}
+
+type testUser struct {
+	Name     string
+	Age      int32
+	Score    float64
+	Color    testColor
+	Birthday time.Time
+	Tags     []string
+	Friends  []*testUser
+	Extra    map[string]int64 `hessian:"ext"`
+}
+
+func (*testUser) JavaClassName() string {
+	return "org.cloudwego.kitex.User"
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		desc     string
+		data     interface{}
+		expected string
+	}{
+		{
+			desc:     "null",
+			data:     nil,
+			expected: "null\n",
+		},
+		{
+			desc:     "string",
+			data:     "hello",
+			expected: "\"hello\"\n",
+		},
+		{
+			desc:     "number",
+			data:     int32(4),
+			expected: "4\n",
+		},
+		{
+			desc:     "map",
+			data:     map[interface{}]interface{}{"k": int64(1), uint16(2): "v"},
+			expected: "{\"2\":\"v\",\"k\":1}\n",
+		},
+		{
+			desc: "pojo",
+			data: &testUser{
+				Name:     "kitex",
+				Age:      18,
+				Color:    testColorRed,
+				Birthday: time.Unix(1, 0),
+				Tags:     []string{"go"},
+			},
+			expected: `{"age":18,"birthday":1000,"color":"RED","ext":null,"friends":null,"name":"kitex","score":0,"tags":["go"]}` + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			e := NewEncoder()
+			assert.Nil(t, e.Encode(test.data))
+			assert.Equal(t, test.expected, string(e.Buffer()))
+		})
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder([]byte("4\n\"hello\"\n1.5\n9999999999\n[1,\"a\"]\n{\"k\":{\"v\":null}}\n"))
+	expected := []interface{}{
+		int32(4), "hello", 1.5, int64(9999999999),
+		[]interface{}{int32(1), "a"},
+		map[interface{}]interface{}{"k": map[interface{}]interface{}{"v": nil}},
+	}
+	for _, want := range expected {
+		got, err := d.Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := d.Decode()
+	assert.NotNil(t, err)
+}
+
+func TestReflectPOJO(t *testing.T) {
+	expected := &testUser{
+		Name:     "kitex",
+		Age:      18,
+		Score:    100,
+		Color:    testColorRed,
+		Birthday: time.Unix(1, 0),
+		Tags:     []string{"go"},
+		Friends:  []*testUser{{Name: "hertz", Color: testColorRed}},
+		Extra:    map[string]int64{"k": 1},
+	}
+	e := NewEncoder()
+	assert.Nil(t, e.Encode(expected))
+
+	v, err := NewDecoder(e.Buffer()).Decode()
+	assert.Nil(t, err)
+	var user *testUser
+	assert.Nil(t, hessian2.ReflectResponse(v, &user))
+	assert.Equal(t, expected.Name, user.Name)
+	assert.Equal(t, expected.Age, user.Age)
+	assert.Equal(t, expected.Score, user.Score)
+	assert.Equal(t, expected.Color, user.Color)
+	assert.True(t, expected.Birthday.Equal(user.Birthday))
+	assert.Equal(t, expected.Tags, user.Tags)
+	assert.Equal(t, expected.Friends[0].Name, user.Friends[0].Name)
+	assert.Equal(t, expected.Extra, user.Extra)
+
+	var users []*testUser
+	assert.Nil(t, hessian2.ReflectResponse([]interface{}{v}, &users))
+	assert.Equal(t, expected.Name, users[0].Name)
+}
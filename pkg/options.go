@@ -22,17 +22,54 @@ package dubbo
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cloudwego/thriftgo/thrift_reflection"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
 	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	// register JSON serializations
+	_ "github.com/kitex-contrib/codec-dubbo/pkg/json"
 )
 
 type Options struct {
-	JavaClassName     string
+	JavaClassName string
+	// store InterfaceName mapping of kitex ServiceName -> java.
+	JavaClassNames map[string]string
+	// store ServiceName mapping of java InterfaceName -> kitex, it is the reverse of JavaClassNames.
+	ServiceNames map[string]string
+	// store group and version of kitex ServiceName -> dubbo service.
+	ServiceKeys map[string]ServiceKey
+	// store ServiceName mapping of dubbo service key (group/InterfaceName:version) -> kitex.
+	KeyedServiceNames map[string]string
+	// KeyedInterfaces are the InterfaceNames dispatched by group and version.
+	KeyedInterfaces   map[string]bool
 	MethodAnnotations map[string]*hessian2.MethodAnnotation
 	// store method name mapping of java -> go.
-	// use the annotation method name + parameter types as the unique identifier.
+	// use the kitex ServiceName + annotation method name + parameter types as the unique identifier.
 	MethodNames map[string]string
+	// store the go methods of java methods, use the kitex ServiceName + annotation method name as the key.
+	// more than one go method means the java method is overloaded.
+	JavaMethods map[string][]string
+	// store the parameter types derived from IDL, use the kitex ServiceName + go method name as the key.
+	// they are sent by client and matched by MethodNames on the server side.
+	MethodTypes map[string]string
+	// store the return types derived from IDL, use the kitex ServiceName + go method name as the key.
+	// void methods are absent.
+	ResultTypes map[string]string
+	// HeartbeatInterval is the idle time after which client sends heartbeat on the connection.
+	HeartbeatInterval time.Duration
+	// HeartbeatThreshold is the number of missed heartbeat replies after which the connection is closed.
+	HeartbeatThreshold int
+	// Serialization is used to encode requests on the client side, hessian2 by default.
+	// Server replies with the serialization of the request.
+	Serialization iface.Serialization
+	// MaxPayload is the max size of the body of dubbo packages, 8MB by default. The limit is disabled if it is <= 0.
+	MaxPayload int
+	// Telnet enables the telnet commands on the provider port.
+	Telnet bool
 }
 
 func (o *Options) Apply(opts []Option) {
@@ -42,11 +79,17 @@ func (o *Options) Apply(opts []Option) {
 }
 
 func newOptions(opts []Option) *Options {
-	o := &Options{}
+	o := &Options{MaxPayload: defaultMaxPayload}
 
 	o.Apply(opts)
-	if o.JavaClassName == "" {
-		panic("DubboCodec must be initialized with JavaClassName. Please use dubbo.WithJavaClassName().")
+	if len(o.ServiceKeys) > 0 {
+		o.applyServiceKeys()
+	}
+	if o.Serialization == nil {
+		o.Serialization, _ = dubbo_spec.GetSerialization(dubbo_spec.SERIALIZATION_ID_HESSIAN)
+	}
+	if o.JavaClassName == "" && len(o.JavaClassNames) == 0 {
+		panic("DubboCodec must be initialized with JavaClassName. Please use dubbo.WithJavaClassName(), dubbo.WithJavaClassNames() or dubbo.WithServiceKeys().")
 	}
 	return o
 }
@@ -63,15 +106,118 @@ func WithJavaClassName(name string) Option {
 	}}
 }
 
+// WithJavaClassNames configures the InterfaceNames of multiple kitex services, the key of names is the kitex ServiceName
+// and the value is the corresponding java InterfaceName.
+// It allows a multi-service kitex server to serve all the InterfaceNames with one DubboCodec, requests would be
+// dispatched to the kitex service according to the requested InterfaceName.
+// For client, the target InterfaceName is specified by the ServiceName of the client.
+// Clients of services not configured in names would fall back to the InterfaceName specified by WithJavaClassName,
+// while multi-service servers reject requests for that InterfaceName since they have no default kitex service.
+func WithJavaClassNames(names map[string]string) Option {
+	serviceNames := make(map[string]string, len(names))
+	for svcName, javaClassName := range names {
+		if javaClassName == "" {
+			panic(fmt.Sprintf("JavaClassName of kitex service %s is empty.", svcName))
+		}
+		if dup, exists := serviceNames[javaClassName]; exists {
+			panic(fmt.Sprintf("JavaClassName %s is configured for both kitex service %s and %s.", javaClassName, dup, svcName))
+		}
+		serviceNames[javaClassName] = svcName
+	}
+
+	return Option{F: func(o *Options) {
+		o.JavaClassNames = names
+		o.ServiceNames = serviceNames
+	}}
+}
+
+// WithServiceKeys configures the InterfaceNames, groups and versions of multiple kitex services, the key of keys is
+// the kitex ServiceName. It allows a server to serve the same InterfaceName with multiple kitex services
+// distinguished by group and version, requests would be dispatched according to the requested group and version,
+// and requests for unknown groups or versions would be rejected.
+// kitex services configured by WithJavaClassNames with the same InterfaceName serve the default group and version.
+func WithServiceKeys(keys map[string]ServiceKey) Option {
+	for svcName, key := range keys {
+		if key.InterfaceName == "" {
+			panic(fmt.Sprintf("InterfaceName of kitex service %s is empty.", svcName))
+		}
+	}
+
+	return Option{F: func(o *Options) {
+		o.ServiceKeys = keys
+	}}
+}
+
+// WithHeartbeat configures the client-side keepalive. Connections idle for interval would send dubbo heartbeat
+// requests, and connections missing threshold replies would be closed. If threshold <= 0, 3 is used by default,
+// which is the same as dubbo-java.
+// It takes effect on the ConnPool wrapped by DubboCodec.ConnPool.
+func WithHeartbeat(interval time.Duration, threshold int) Option {
+	if threshold <= 0 {
+		threshold = defaultHeartbeatThreshold
+	}
+	return Option{F: func(o *Options) {
+		o.HeartbeatInterval = interval
+		o.HeartbeatThreshold = threshold
+	}}
+}
+
+// WithSerialization specifies the serialization used by client to encode requests, e.g. hessian2, fastjson, gson.
+// Server accepts requests of all the registered serializations regardless of this option.
+func WithSerialization(name string) Option {
+	s, ok := dubbo_spec.GetSerializationByName(name)
+	if !ok {
+		panic(fmt.Sprintf("Serialization %s is not registered.", name))
+	}
+	return Option{F: func(o *Options) {
+		o.Serialization = s
+	}}
+}
+
+// WithMaxPayload specifies the max size of the body of dubbo packages, which is the same as the payload option of
+// dubbo-java and is 8MB by default. size <= 0 disables the limit.
+// Client fails requests with ExceedPayloadLimitError before sending them or reading their responses.
+// Server discards the body of requests exceeding the limit without decoding it and replies them with StatusBadRequest,
+// the connection keeps serving the other requests. Server replies with StatusBadResponse if the response exceeds the limit.
+func WithMaxPayload(size int) Option {
+	return Option{F: func(o *Options) {
+		o.MaxPayload = size
+	}}
+}
+
+// WithTelnet enables the telnet commands on the provider port, which are used by SREs to debug dubbo-java providers
+// with `telnet host port`. Connections starting with text instead of the dubbo magic are served as telnet sessions:
+//   - ls [-l] [service]: list the services and the methods, methods are described by WithFileDescriptor.
+//   - invoke [service.]method(args): invoke the method with arguments in JSON.
+//   - count service [method]: show the invocation statistics.
+//   - status [-l]: show the status of the provider.
+//   - online/offline [service]: register or deregister the services with the registry wrapped by
+//     DubboCodec.TelnetRegistry.
+func WithTelnet() Option {
+	return Option{F: func(o *Options) {
+		o.Telnet = true
+	}}
+}
+
 // WithFileDescriptor provides method annotations for DubboCodec.
 // Adding method annotations allows you to specify the method parameters and method name on the Java side.
+// The parameter types of methods are derived from the IDL, which are deterministic regardless of argument contents.
+// Multiple methods could be mapped to one overloaded Java method by JavaMethodName, which are distinguished by their
+// parameter types. It panics if overloaded methods have the same parameter types, or the parameter types of a method
+// could not be derived.
 func WithFileDescriptor(fd *thrift_reflection.FileDescriptor) Option {
 	if fd == nil {
 		panic("Please pass in a valid FileDescriptor.")
 	}
+	parsed := new(Options)
+	parseAnnotations(parsed, fd)
 
 	return Option{F: func(o *Options) {
-		parseAnnotations(o, fd)
+		o.MethodAnnotations = parsed.MethodAnnotations
+		o.MethodNames = parsed.MethodNames
+		o.JavaMethods = parsed.JavaMethods
+		o.MethodTypes = parsed.MethodTypes
+		o.ResultTypes = parsed.ResultTypes
 	}}
 }
 
@@ -79,24 +225,53 @@ func WithFileDescriptor(fd *thrift_reflection.FileDescriptor) Option {
 func parseAnnotations(o *Options, fd *thrift_reflection.FileDescriptor) {
 	o.MethodAnnotations = make(map[string]*hessian2.MethodAnnotation)
 	o.MethodNames = make(map[string]string)
+	o.JavaMethods = make(map[string][]string)
+	o.MethodTypes = make(map[string]string)
+	o.ResultTypes = make(map[string]string)
 
+	var conflicts []string
 	for _, svc := range fd.GetServices() {
 		prefix := svc.GetName() + "."
+		javaMethods := make(map[string][]string)
 
 		for _, m := range svc.GetMethods() {
 			ma := hessian2.NewMethodAnnotation(m.GetAnnotations())
 			o.MethodAnnotations[prefix+m.GetName()] = ma
-			params := getMethodParams(m, ma)
 
-			if method, exists := ma.GetMethodName(); exists {
-				types, err := hessian2.GetParamsTypeList(params)
+			method, exists := ma.GetMethodName()
+			if !exists {
+				method = m.GetName()
+			}
+			javaMethods[method] = append(javaMethods[method], m.GetName())
+			// the parameter types are derived from IDL instead of the runtime values of arguments,
+			// so that they are deterministic regardless of the argument contents.
+			types, err := hessian2.GetParamsTypeList(getMethodParams(m, ma))
+			if err != nil {
+				panic(fmt.Sprintf("Get method %s parameter types failed: %s", m.GetName(), err.Error()))
+			}
+			o.MethodTypes[prefix+m.GetName()] = types
+			if result := getMethodResult(m); result != nil {
+				resultTypes, err := hessian2.GetParamsTypeList([]*hessian2.Parameter{result})
 				if err != nil {
-					panic(fmt.Sprintf("Get method %s parameter types failed: %s", m.GetName(), err.Error()))
+					panic(fmt.Sprintf("Get method %s result type failed: %s", m.GetName(), err.Error()))
 				}
-				o.MethodNames[method+types] = m.GetName()
+				o.ResultTypes[prefix+m.GetName()] = resultTypes
+			}
+			if dup, ok := o.MethodNames[prefix+method+types]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("%s%s(%s) is mapped by both %s and %s", prefix, method, types, dup, m.GetName()))
+				continue
 			}
+			o.MethodNames[prefix+method+types] = m.GetName()
+		}
+
+		for method, methods := range javaMethods {
+			o.JavaMethods[prefix+method] = methods
 		}
 	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		panic(fmt.Sprintf("Overloaded methods have the same parameter types: %s.", strings.Join(conflicts, "; ")))
+	}
 }
 
 // getMethodParams get the parameter list of a method.
@@ -107,8 +282,30 @@ func getMethodParams(m *thrift_reflection.MethodDescriptor, ma *hessian2.MethodA
 		if err != nil {
 			panic(fmt.Sprintf("obtain the type of parameter %s in method %s failed: %s", a.GetName(), m.GetName(), err.Error()))
 		}
-		val := reflect.New(typ).Elem().Interface()
+		val := reflect.New(kitexType(typ)).Elem().Interface()
 		params[i] = hessian2.NewParameter(val, ma.GetFieldType(i))
 	}
 	return params
 }
+
+// getMethodResult get the result of a method, nil is returned for void methods.
+func getMethodResult(m *thrift_reflection.MethodDescriptor) *hessian2.Parameter {
+	resp := m.GetResponse()
+	if resp == nil || resp.GetName() == "void" {
+		return nil
+	}
+	typ, err := resp.GetGoType()
+	if err != nil {
+		panic(fmt.Sprintf("obtain the type of result in method %s failed: %s", m.GetName(), err.Error()))
+	}
+	return hessian2.NewParameter(reflect.New(kitexType(typ)).Elem().Interface(), "")
+}
+
+// kitexType returns the go type generated by kitex for the type reflected by thriftgo.
+// thrift byte is reflected as uint8, while kitex generates int8 for it.
+func kitexType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Uint8 {
+		return reflect.TypeOf(int8(0))
+	}
+	return typ
+}
@@ -0,0 +1,335 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/cloudwego/thriftgo/thrift_reflection"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithJavaClassNames(t *testing.T) {
+	tests := []struct {
+		desc     string
+		names    map[string]string
+		expected func(t *testing.T, o *Options)
+		panic    bool
+	}{
+		{
+			desc: "multiple services",
+			names: map[string]string{
+				"GreetService":     "org.cloudwego.kitex.samples.api.GreetProvider",
+				"GreetEnumService": "org.cloudwego.kitex.samples.api.GreetEnumProvider",
+			},
+			expected: func(t *testing.T, o *Options) {
+				assert.Equal(t, "org.cloudwego.kitex.samples.api.GreetProvider", o.JavaClassNames["GreetService"])
+				assert.Equal(t, "GreetService", o.ServiceNames["org.cloudwego.kitex.samples.api.GreetProvider"])
+				assert.Equal(t, "GreetEnumService", o.ServiceNames["org.cloudwego.kitex.samples.api.GreetEnumProvider"])
+			},
+		},
+		{
+			desc: "duplicate JavaClassName",
+			names: map[string]string{
+				"GreetService":     "org.cloudwego.kitex.samples.api.GreetProvider",
+				"GreetEnumService": "org.cloudwego.kitex.samples.api.GreetProvider",
+			},
+			panic: true,
+		},
+		{
+			desc: "empty JavaClassName",
+			names: map[string]string{
+				"GreetService": "",
+			},
+			panic: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if test.panic {
+				assert.Panics(t, func() {
+					newOptions([]Option{WithJavaClassNames(test.names)})
+				})
+				return
+			}
+			o := newOptions([]Option{WithJavaClassNames(test.names)})
+			test.expected(t, o)
+		})
+	}
+}
+
+// optionsTestArgs is the same as the args generated by kitex for Greet(req string, size i32).
+type optionsTestArgs struct {
+	Req  string
+	Size int32
+}
+
+func (p *optionsTestArgs) Encode(e iface.Encoder) error {
+	if err := e.Encode(p.Req); err != nil {
+		return err
+	}
+	return e.Encode(p.Size)
+}
+
+func (p *optionsTestArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Req); err != nil {
+		return err
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Size)
+}
+
+func newOptionsTestServiceInfo(svcName string, methods ...string) *serviceinfo.ServiceInfo {
+	svcInfo := &serviceinfo.ServiceInfo{ServiceName: svcName, Methods: make(map[string]serviceinfo.MethodInfo)}
+	for _, method := range methods {
+		svcInfo.Methods[method] = serviceinfo.NewMethodInfo(nil,
+			func() interface{} { return new(optionsTestArgs) },
+			func() interface{} { return new(optionsTestArgs) },
+			false,
+		)
+	}
+	return svcInfo
+}
+
+// newOptionsTestRequest encodes the request of Greet(req string, size i32) with hessian2.
+func newOptionsTestRequest(t *testing.T, path, method, types string) remote.ByteBuffer {
+	encoder := hessian2.NewEncoder()
+	for _, v := range []interface{}{
+		dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, path, "", method, types,
+		"world", int32(1), map[interface{}]interface{}{},
+	} {
+		assert.Nil(t, encoder.Encode(v))
+	}
+	body := encoder.Buffer()
+	header := &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+		DataLength:      uint32(len(body)),
+	}
+	return remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))
+}
+
+func TestMultiServiceDispatch(t *testing.T) {
+	const echoJavaClassName = "org.cloudwego.kitex.samples.api.EchoProvider"
+	codec := NewDubboCodec(
+		WithJavaClassName("org.cloudwego.kitex.samples.api.DefaultProvider"),
+		WithJavaClassNames(map[string]string{
+			"GreetService": testJavaClassName,
+			"EchoService":  echoJavaClassName,
+		}),
+		WithFileDescriptor(&thrift_reflection.FileDescriptor{
+			Services: []*thrift_reflection.ServiceDescriptor{
+				{Name: "GreetService", Methods: []*thrift_reflection.MethodDescriptor{
+					newTestMethodDescriptor("Greet", "greet", "string", "i32"),
+				}},
+				{Name: "EchoService", Methods: []*thrift_reflection.MethodDescriptor{
+					newTestMethodDescriptor("Echo", "echo", "string", "i32"),
+				}},
+			},
+		}),
+	)
+	svcSearchMap := map[string]*serviceinfo.ServiceInfo{
+		remote.BuildMultiServiceKey("GreetService", "Greet"): newOptionsTestServiceInfo("GreetService", "Greet"),
+		remote.BuildMultiServiceKey("EchoService", "Echo"):   newOptionsTestServiceInfo("EchoService", "Echo"),
+	}
+
+	tests := []struct {
+		desc            string
+		path            string
+		method          string
+		expectedService string
+		expectedMethod  string
+	}{
+		{desc: "GreetService", path: testJavaClassName, method: "greet", expectedService: "GreetService", expectedMethod: "Greet"},
+		{desc: "EchoService", path: echoJavaClassName, method: "echo", expectedService: "EchoService", expectedMethod: "Echo"},
+		// the method of the other service
+		{desc: "unknown method", path: echoJavaClassName, method: "greet"},
+		{desc: "unknown InterfaceName", path: "org.cloudwego.kitex.samples.api.UnknownProvider", method: "greet"},
+		// no default kitex service serves JavaClassName in multi-service servers
+		{desc: "default InterfaceName", path: "org.cloudwego.kitex.samples.api.DefaultProvider", method: "greet"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			recvMsg := remote.NewMessageWithNewer(nil, svcSearchMap, ri, remote.Call, remote.Server, false)
+			in := newOptionsTestRequest(t, test.path, test.method, "Ljava/lang/String;Ljava/lang/Integer;")
+			err := codec.Decode(context.Background(), recvMsg, in)
+			assert.Nil(t, err)
+			if test.expectedService == "" {
+				// the request is rejected with the status without invoking the handler
+				assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+				rejection, _ := getRejection(recvMsg)
+				assert.Equal(t, dubbo_spec.StatusServiceNotFound, getRejectionStatus(rejection))
+				return
+			}
+			assert.Equal(t, test.expectedService, recvMsg.ServiceInfo().ServiceName)
+			assert.Equal(t, test.expectedMethod, ri.Invocation().MethodName())
+			assert.Equal(t, &optionsTestArgs{Req: "world", Size: 1}, recvMsg.Data())
+		})
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	assert.Panics(t, func() {
+		newOptions(nil)
+	})
+	assert.NotPanics(t, func() {
+		newOptions([]Option{WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider")})
+	})
+}
+
+func TestWithSerialization(t *testing.T) {
+	o := newOptions([]Option{WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider")})
+	assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_HESSIAN), o.Serialization.ID())
+	o = newOptions([]Option{
+		WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"),
+		WithSerialization("gson"),
+	})
+	assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_GSON), o.Serialization.ID())
+	assert.Panics(t, func() {
+		WithSerialization("kryo")
+	})
+}
+
+// newTestMethodDescriptor creates a MethodDescriptor with basic argument types mapped to javaMethodName.
+func newTestMethodDescriptor(name, javaMethodName string, argTypes ...string) *thrift_reflection.MethodDescriptor {
+	md := &thrift_reflection.MethodDescriptor{Name: name, Annotations: map[string][]string{}}
+	if javaMethodName != "" {
+		md.Annotations[hessian2.HESSIAN_JAVA_METHOD_NAME_TAG] = []string{javaMethodName}
+	}
+	for _, typ := range argTypes {
+		md.Args = append(md.Args, &thrift_reflection.FieldDescriptor{Type: &thrift_reflection.TypeDescriptor{Name: typ}})
+	}
+	return md
+}
+
+func newTestFileDescriptor(methods ...*thrift_reflection.MethodDescriptor) *thrift_reflection.FileDescriptor {
+	return &thrift_reflection.FileDescriptor{
+		Services: []*thrift_reflection.ServiceDescriptor{{Name: "GreetService", Methods: methods}},
+	}
+}
+
+func TestWithFileDescriptor(t *testing.T) {
+	o := newOptions([]Option{
+		WithJavaClassName(testJavaClassName),
+		WithFileDescriptor(newTestFileDescriptor(
+			newTestMethodDescriptor("Greet", "", "string", "i32"),
+			newTestMethodDescriptor("GreetString", "greet", "string"),
+			newTestMethodDescriptor("GreetLong", "greet", "i64"),
+			newTestMethodDescriptor("GreetByte", "", "byte"),
+		)),
+	})
+	assert.Equal(t, map[string]string{
+		"GreetService.GreetLjava/lang/String;Ljava/lang/Integer;": "Greet",
+		"GreetService.greetLjava/lang/String;":                    "GreetString",
+		"GreetService.greetLjava/lang/Long;":                      "GreetLong",
+		"GreetService.GreetByteLjava/lang/Byte;":                  "GreetByte",
+	}, o.MethodNames)
+	assert.Equal(t, map[string][]string{
+		"GreetService.Greet":     {"Greet"},
+		"GreetService.greet":     {"GreetString", "GreetLong"},
+		"GreetService.GreetByte": {"GreetByte"},
+	}, o.JavaMethods)
+	assert.Equal(t, map[string]string{
+		"GreetService.Greet":       "Ljava/lang/String;Ljava/lang/Integer;",
+		"GreetService.GreetString": "Ljava/lang/String;",
+		"GreetService.GreetLong":   "Ljava/lang/Long;",
+		"GreetService.GreetByte":   "Ljava/lang/Byte;",
+	}, o.MethodTypes)
+
+	assert.PanicsWithValue(t, "Overloaded methods have the same parameter types: "+
+		"GreetService.greet(Ljava/lang/String;) is mapped by both GreetString and GreetText.", func() {
+		WithFileDescriptor(newTestFileDescriptor(
+			newTestMethodDescriptor("GreetString", "greet", "string"),
+			newTestMethodDescriptor("GreetText", "greet", "string"),
+			newTestMethodDescriptor("GreetLong", "greet", "i64"),
+		))
+	})
+}
+
+func TestOverloadedMethods(t *testing.T) {
+	codec := NewDubboCodec(
+		WithJavaClassName(testJavaClassName),
+		WithFileDescriptor(newTestFileDescriptor(
+			// the IDL types differ from the runtime values of optionsTestArgs
+			newTestMethodDescriptor("Greet", "greet", "string", "i64"),
+			newTestMethodDescriptor("GreetString", "greet", "string"),
+			newTestMethodDescriptor("Hello", "hello", "string", "i32"),
+		)),
+	)
+
+	t.Run("client", func(t *testing.T) {
+		ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("GreetService", "Greet", nil, nil),
+			rpcinfo.NewInvocation("GreetService", "Greet"), rpcinfo.NewRPCConfig(), nil)
+		sendMsg := remote.NewMessage(&optionsTestArgs{Req: "world"}, newOptionsTestServiceInfo("GreetService", "Greet"), ri,
+			remote.Call, remote.Client)
+		buf := remote.NewReaderWriterBuffer(1024)
+		assert.Nil(t, codec.Encode(context.Background(), sendMsg, buf))
+		out, err := buf.Bytes()
+		assert.Nil(t, err)
+
+		decoder := hessian2.NewDecoder(out[dubbo_spec.HEADER_SIZE:])
+		service := new(dubbo_spec.Service)
+		assert.Nil(t, service.Decode(decoder))
+		assert.Equal(t, "greet", service.Method)
+		var types string
+		assert.Nil(t, dubbo_spec.DecodeTo(decoder, &types))
+		// the types specified by IDL instead of the runtime values
+		assert.Equal(t, "Ljava/lang/String;Ljava/lang/Long;", types)
+	})
+
+	tests := []struct {
+		desc     string
+		method   string
+		types    string
+		expected string
+	}{
+		{desc: "overloaded", method: "greet", types: "Ljava/lang/String;Ljava/lang/Long;", expected: "Greet"},
+		// types are not matched, the method name is used since hello is not overloaded
+		{desc: "name only", method: "hello", types: "Ljava/lang/String;I", expected: "Hello"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			recvMsg := remote.NewMessage(nil, newOptionsTestServiceInfo("GreetService", "Greet", "Hello"), ri,
+				remote.Call, remote.Server)
+			in := newOptionsTestRequest(t, testJavaClassName, test.method, test.types)
+			assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+			assert.Equal(t, test.expected, ri.Invocation().MethodName())
+			assert.Equal(t, &optionsTestArgs{Req: "world", Size: 1}, recvMsg.Data())
+		})
+	}
+}
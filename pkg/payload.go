@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/remote/codec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+// defaultMaxPayload is the same as the default payload of dubbo-java, 8MB.
+const defaultMaxPayload = 8 * 1024 * 1024
+
+// ExceedPayloadLimitError is returned when the body of a dubbo package is larger than the max payload configured by
+// WithMaxPayload, which is the same as ExceedPayloadLimitException of dubbo-java.
+//   - client returns it before sending the request or reading the body of the response.
+//   - server discards the body of the request and replies it with StatusBadRequest without closing the connection,
+//     and replies with StatusBadResponse if the response is too large.
+type ExceedPayloadLimitError struct {
+	Size       int
+	MaxPayload int
+}
+
+func (e *ExceedPayloadLimitError) Error() string {
+	return fmt.Sprintf("Data length too large: %d, max payload: %d", e.Size, e.MaxPayload)
+}
+
+// checkPayload returns ExceedPayloadLimitError if size exceeds maxPayload, the limit is disabled if maxPayload <= 0.
+func checkPayload(size, maxPayload int) error {
+	if maxPayload > 0 && size > maxPayload {
+		return &ExceedPayloadLimitError{Size: size, MaxPayload: maxPayload}
+	}
+	return nil
+}
+
+// rejectRequest discards the body of the request rejected by err when decoding its header on the server side,
+// and rejects it by rejectInvocation so that it is replied with StatusBadRequest and its RequestID without
+// closing the connection. err is returned for the other packages.
+func rejectRequest(header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer, err error) error {
+	if message.RPCRole() != remote.Server || !header.IsRequest {
+		return err
+	}
+	if seqErr := codec.SetOrCheckSeqID(int32(header.RequestID), message); seqErr != nil {
+		return seqErr
+	}
+	if skipErr := in.Skip(int(header.DataLength)); skipErr != nil {
+		return skipErr
+	}
+	return rejectInvocation(message, err, remote.ProtocolError)
+}
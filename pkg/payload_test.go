@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxPayload(t *testing.T) {
+	assert.Equal(t, defaultMaxPayload, newOptions([]Option{WithJavaClassName(testJavaClassName)}).MaxPayload)
+	assert.Equal(t, 1024, newOptions([]Option{WithJavaClassName(testJavaClassName), WithMaxPayload(1024)}).MaxPayload)
+
+	assert.Nil(t, checkPayload(1<<30, 0))
+}
+
+func TestServerMaxPayload(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithMaxPayload(64))
+	svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+
+	t.Run("request", func(t *testing.T) {
+		header := &dubbo_spec.DubboHeader{
+			IsRequest:       true,
+			SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+			RequestID:       5,
+			DataLength:      65,
+		}
+		ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+			rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+		recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+		// the body is discarded without being decoded, and the request is rejected without closing the connection
+		in := remote.NewReaderBuffer(append(header.EncodeToByteSlice(), make([]byte, 65)...))
+		assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+		assert.Equal(t, 0, in.ReadableLen())
+		assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+		rejection, ok := getRejection(recvMsg)
+		assert.True(t, ok)
+		var transErr *remote.TransError
+		assert.True(t, errors.As(rejection, &transErr))
+		var payloadErr *ExceedPayloadLimitError
+		assert.True(t, errors.As(rejection, &payloadErr))
+		assert.Equal(t, 65, payloadErr.Size)
+		assert.Equal(t, 64, payloadErr.MaxPayload)
+
+		sendMsg := remote.NewMessage(nil, svcInfo, ri, remote.Heartbeat, remote.Server)
+		out := remote.NewReaderWriterBuffer(1024)
+		assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+		buf, err := out.Bytes()
+		assert.Nil(t, err)
+		respHeader := new(dubbo_spec.DubboHeader)
+		assert.Nil(t, respHeader.DecodeFromByteSlice(buf))
+		assert.Equal(t, dubbo_spec.StatusBadRequest, respHeader.Status)
+		assert.Equal(t, uint64(5), respHeader.RequestID)
+		msg, err := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:]).Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, "Data length too large: 65, max payload: 64", msg)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+			rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+		sendMsg := remote.NewMessage(&GenericInvokeResult{Success: strings.Repeat("a", 64)}, svcInfo, ri, remote.Reply, remote.Server)
+		out := remote.NewReaderWriterBuffer(1024)
+		assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+		buf, err := out.Bytes()
+		assert.Nil(t, err)
+		respHeader := new(dubbo_spec.DubboHeader)
+		assert.Nil(t, respHeader.DecodeFromByteSlice(buf))
+		assert.Equal(t, dubbo_spec.StatusBadResponse, respHeader.Status)
+		msg, err := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:]).Decode()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(msg.(string), "Data length too large: "))
+	})
+}
+
+func TestClientMaxPayload(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithMaxPayload(64))
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", GenericInvokeMethod, nil, nil),
+		rpcinfo.NewInvocation(GenericServiceName, GenericInvokeMethod), rpcinfo.NewRPCConfig(), nil)
+
+	t.Run("request", func(t *testing.T) {
+		sendMsg := remote.NewMessage(&GenericInvokeArgs{
+			Method:         "Greet",
+			ParameterTypes: []string{"java.lang.String"},
+			Args:           []interface{}{"world"},
+		}, genericServiceInfo, ri, remote.Call, remote.Client)
+		out := remote.NewReaderWriterBuffer(1024)
+		err := codec.Encode(context.Background(), sendMsg, out)
+		var payloadErr *ExceedPayloadLimitError
+		assert.True(t, errors.As(err, &payloadErr))
+		assert.Equal(t, 64, payloadErr.MaxPayload)
+		assert.Equal(t, 0, out.ReadableLen())
+	})
+
+	t.Run("response", func(t *testing.T) {
+		header := &dubbo_spec.DubboHeader{
+			SerializationID: dubbo_spec.SERIALIZATION_ID_HESSIAN,
+			Status:          dubbo_spec.StatusOK,
+			RequestID:       uint64(ri.Invocation().SeqID()),
+			DataLength:      1 << 30,
+		}
+		recvMsg := remote.NewMessage(new(GenericInvokeResult), genericServiceInfo, ri, remote.Reply, remote.Client)
+		err := codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(header.EncodeToByteSlice()))
+		var payloadErr *ExceedPayloadLimitError
+		assert.True(t, errors.As(err, &payloadErr))
+		assert.Equal(t, 1<<30, payloadErr.Size)
+	})
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protobuf
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Message is implemented by the args and results generated by kitex for protobuf IDL,
+// which marshal the only argument or the result as a protobuf message.
+type Message interface {
+	Marshal(out []byte) ([]byte, error)
+	Unmarshal(in []byte) error
+}
+
+// NewMessage adapts msg to iface.Message, it could only be encoded and decoded by protobuf serialization.
+func NewMessage(msg Message) iface.Message {
+	return &message{msg: msg}
+}
+
+type message struct {
+	msg Message
+}
+
+func (m *message) Encode(e iface.Encoder) error {
+	if _, ok := e.(*Encoder); !ok {
+		return fmt.Errorf("%T generated by kitex protobuf could only be encoded by protobuf serialization", m.msg)
+	}
+	return e.Encode(m.msg)
+}
+
+func (m *message) Decode(d iface.Decoder) error {
+	decoder, ok := d.(*Decoder)
+	if !ok {
+		return fmt.Errorf("%T generated by kitex protobuf could only be decoded by protobuf serialization", m.msg)
+	}
+	return decoder.DecodeTo(m.msg)
+}
+
+// GetTypes returns the parameter types of the args generated by kitex protobuf, e.g. Lcom/example/HelloRequest;
+func GetTypes(args interface{}) (string, error) {
+	arg, ok := args.(interface{ GetFirstArgument() interface{} })
+	if !ok {
+		return "", fmt.Errorf("%T is not the args generated by kitex protobuf", args)
+	}
+	msg, ok := arg.GetFirstArgument().(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("argument of %T is not a protobuf message", args)
+	}
+	return "L" + strings.ReplaceAll(JavaClassName(msg.ProtoReflect().Descriptor()), ".", "/") + ";", nil
+}
+
+// JavaClassName returns the java class name generated by protoc for md according to the java options of its file,
+// nested classes are separated by '$'.
+func JavaClassName(md protoreflect.MessageDescriptor) string {
+	fd := md.ParentFile()
+	opts, _ := fd.Options().(*descriptorpb.FileOptions)
+
+	name := strings.TrimPrefix(string(md.FullName()), string(fd.Package())+".")
+	name = strings.ReplaceAll(name, ".", "$")
+	if !opts.GetJavaMultipleFiles() {
+		outerClassName := opts.GetJavaOuterClassname()
+		if outerClassName == "" {
+			outerClassName = defaultOuterClassName(fd)
+		}
+		name = outerClassName + "$" + name
+	}
+
+	javaPackage := opts.GetJavaPackage()
+	if javaPackage == "" {
+		javaPackage = string(fd.Package())
+	}
+	if javaPackage == "" {
+		return name
+	}
+	return javaPackage + "." + name
+}
+
+// defaultOuterClassName converts the file name to camel case in the same way as protoc,
+// "OuterClass" is appended if it conflicts with the top-level types.
+func defaultOuterClassName(fd protoreflect.FileDescriptor) string {
+	base := strings.TrimSuffix(path.Base(fd.Path()), ".proto")
+	var sb strings.Builder
+	capNext := true
+	for _, c := range base {
+		switch {
+		case c >= 'a' && c <= 'z':
+			if capNext {
+				c -= 'a' - 'A'
+			}
+			sb.WriteRune(c)
+			capNext = false
+		case c >= 'A' && c <= 'Z':
+			sb.WriteRune(c)
+			capNext = false
+		case c >= '0' && c <= '9':
+			sb.WriteRune(c)
+			capNext = true
+		default:
+			capNext = true
+		}
+	}
+	name := sb.String()
+
+	conflict := fd.Messages().ByName(protoreflect.Name(name)) != nil ||
+		fd.Enums().ByName(protoreflect.Name(name)) != nil ||
+		fd.Services().ByName(protoreflect.Name(name)) != nil
+	if conflict {
+		name += "OuterClass"
+	}
+	return name
+}
@@ -0,0 +1,355 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package protobuf implements the protobuf serialization of dubbo, which is compatible with
+// GenericProtobufObjectOutput and GenericProtobufObjectInput of dubbo-java.
+// Each value is written as a length-delimited protobuf message: primitives are wrapped by the well-known wrapper types,
+// attachments are wrapped by MapValue.Map and exceptions are wrapped by ThrowableProto.
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	SerializationName = "protobuf"
+
+	// defaultThrowableClassName is used for errors not specifying java class name, which is the same as hessian2.
+	defaultThrowableClassName = "java.lang.Exception"
+)
+
+// field numbers of MapValue.Map and ThrowableProto defined by dubbo-serialization-protobuf
+const (
+	mapAttachmentsField = 1
+	mapEntryKeyField    = 1
+	mapEntryValueField  = 2
+
+	throwableClassNameField = 1
+	throwableMessageField   = 2
+)
+
+var errDecodeWithoutType = errors.New("protobuf values could not be decoded without type, use DecodeTo instead")
+
+func init() {
+	dubbo_spec.RegisterSerialization(serialization{})
+}
+
+type serialization struct{}
+
+func (serialization) ID() uint8 {
+	return dubbo_spec.SERIALIZATION_ID_PROTOBUF
+}
+
+func (serialization) Name() string {
+	return SerializationName
+}
+
+func (serialization) NewEncoder() iface.Encoder {
+	return NewEncoder()
+}
+
+func (serialization) NewDecoder(b []byte) iface.Decoder {
+	return NewDecoder(b)
+}
+
+// RawMessage is an encoded protobuf message whose type is unknown, e.g. the argument of $echo.
+type RawMessage []byte
+
+// Throwable is the exception decoded from ThrowableProto.
+type Throwable struct {
+	OriginalClassName string
+	OriginalMessage   string
+}
+
+func (t *Throwable) Error() string {
+	return t.OriginalMessage
+}
+
+func (t *Throwable) JavaClassName() string {
+	return t.OriginalClassName
+}
+
+// Encoder writes values as length-delimited protobuf messages.
+type Encoder struct {
+	buf []byte
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (e *Encoder) Encode(v interface{}) error {
+	msg, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	e.buf = protowire.AppendBytes(e.buf, msg)
+	return nil
+}
+
+func (e *Encoder) Buffer() []byte {
+	return e.buf
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		// null could not be encoded by protobuf, it is only written for heartbeat events
+		return proto.Marshal(wrapperspb.String(dubbo_spec.MOCK_HEARTBEAT_EVENT))
+	case RawMessage:
+		return val, nil
+	case Message:
+		return val.Marshal(nil)
+	case proto.Message:
+		return proto.Marshal(val)
+	case error:
+		return marshalThrowable(val), nil
+	case string:
+		return proto.Marshal(wrapperspb.String(val))
+	case bool:
+		return proto.Marshal(wrapperspb.Bool(val))
+	case []byte:
+		return proto.Marshal(wrapperspb.Bytes(val))
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return proto.Marshal(wrapperspb.Int32(int32(rv.Int())))
+	case reflect.Int, reflect.Int64:
+		return proto.Marshal(wrapperspb.Int64(rv.Int()))
+	case reflect.Float32:
+		return proto.Marshal(wrapperspb.Float(float32(rv.Float())))
+	case reflect.Float64:
+		return proto.Marshal(wrapperspb.Double(rv.Float()))
+	case reflect.Map:
+		return marshalMap(rv), nil
+	}
+	return nil, fmt.Errorf("protobuf serialization does not support %T", v)
+}
+
+// marshalMap marshals attachments to MapValue.Map, which only supports string keys and values.
+// Other keys and values are converted to strings, and entries are sorted by key to keep the output stable.
+func marshalMap(rv reflect.Value) []byte {
+	attachments := make(map[string]string, rv.Len())
+	keys := make([]string, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+		attachments[key] = fmt.Sprint(iter.Value().Interface())
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var msg []byte
+	for _, key := range keys {
+		var entry []byte
+		entry = protowire.AppendTag(entry, mapEntryKeyField, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		entry = protowire.AppendTag(entry, mapEntryValueField, protowire.BytesType)
+		entry = protowire.AppendString(entry, attachments[key])
+		msg = protowire.AppendTag(msg, mapAttachmentsField, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, entry)
+	}
+	return msg
+}
+
+// marshalThrowable marshals err to ThrowableProto, the stack trace and cause are omitted.
+func marshalThrowable(err error) []byte {
+	className := defaultThrowableClassName
+	if pojo, ok := err.(interface{ JavaClassName() string }); ok {
+		className = pojo.JavaClassName()
+	}
+	var msg []byte
+	msg = protowire.AppendTag(msg, throwableClassNameField, protowire.BytesType)
+	msg = protowire.AppendString(msg, className)
+	msg = protowire.AppendTag(msg, throwableMessageField, protowire.BytesType)
+	msg = protowire.AppendString(msg, err.Error())
+	return msg
+}
+
+// Decoder reads length-delimited protobuf messages, which could only be decoded by DecodeTo with the expected type.
+type Decoder struct {
+	buf []byte
+}
+
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{buf: b}
+}
+
+// Clone returns a decoder reading the rest messages independently of d.
+func (d *Decoder) Clone() iface.Decoder {
+	return &Decoder{buf: d.buf}
+}
+
+// Decode always fails since protobuf messages carry no type information.
+func (d *Decoder) Decode() (interface{}, error) {
+	return nil, errDecodeWithoutType
+}
+
+// DecodeTo decodes the next message into v, which could be a pointer to primitives, map[interface{}]interface{},
+// error, interface{} (decoded as RawMessage), or protobuf messages including args and results generated by kitex.
+func (d *Decoder) DecodeTo(v interface{}) error {
+	msg, n := protowire.ConsumeBytes(d.buf)
+	if n < 0 {
+		return fmt.Errorf("decode protobuf message failed: %s", protowire.ParseError(n))
+	}
+	d.buf = d.buf[n:]
+
+	switch dest := v.(type) {
+	case *interface{}:
+		*dest = RawMessage(msg)
+	case *RawMessage:
+		*dest = msg
+	case Message:
+		return dest.Unmarshal(msg)
+	case proto.Message:
+		return proto.Unmarshal(msg, dest)
+	case *error:
+		throwable, err := unmarshalThrowable(msg)
+		if err != nil {
+			return err
+		}
+		*dest = throwable
+	case *map[interface{}]interface{}:
+		attachments, err := unmarshalMap(msg)
+		if err != nil {
+			return err
+		}
+		*dest = attachments
+	case *string:
+		wrapper := new(wrapperspb.StringValue)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *bool:
+		wrapper := new(wrapperspb.BoolValue)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *[]byte:
+		wrapper := new(wrapperspb.BytesValue)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *int32:
+		wrapper := new(wrapperspb.Int32Value)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *int64:
+		wrapper := new(wrapperspb.Int64Value)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *float32:
+		wrapper := new(wrapperspb.FloatValue)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	case *float64:
+		wrapper := new(wrapperspb.DoubleValue)
+		if err := proto.Unmarshal(msg, wrapper); err != nil {
+			return err
+		}
+		*dest = wrapper.Value
+	default:
+		return fmt.Errorf("protobuf serialization does not support decoding to %T", v)
+	}
+	return nil
+}
+
+func unmarshalMap(msg []byte) (map[interface{}]interface{}, error) {
+	attachments := make(map[interface{}]interface{})
+	err := consumeFields(msg, func(num protowire.Number, field []byte) error {
+		if num != mapAttachmentsField {
+			return nil
+		}
+		var key, value string
+		err := consumeFields(field, func(num protowire.Number, field []byte) error {
+			switch num {
+			case mapEntryKeyField:
+				key = string(field)
+			case mapEntryValueField:
+				value = string(field)
+			}
+			return nil
+		})
+		attachments[key] = value
+		return err
+	})
+	return attachments, err
+}
+
+func unmarshalThrowable(msg []byte) (*Throwable, error) {
+	throwable := new(Throwable)
+	err := consumeFields(msg, func(num protowire.Number, field []byte) error {
+		switch num {
+		case throwableClassNameField:
+			throwable.OriginalClassName = string(field)
+		case throwableMessageField:
+			throwable.OriginalMessage = string(field)
+		}
+		return nil
+	})
+	return throwable, err
+}
+
+// consumeFields iterates the length-delimited fields of msg, fields of other types are skipped.
+func consumeFields(msg []byte, f func(num protowire.Number, field []byte) error) error {
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, msg)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			msg = msg[n:]
+			continue
+		}
+		field, n := protowire.ConsumeBytes(msg)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		if err := f(num, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package protobuf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestEncoderDecoder(t *testing.T) {
+	tests := []struct {
+		desc     string
+		data     interface{}
+		dest     func() interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "string",
+			data:     "hello",
+			dest:     func() interface{} { return new(string) },
+			expected: "hello",
+		},
+		{
+			desc:     "empty string",
+			data:     "",
+			dest:     func() interface{} { return new(string) },
+			expected: "",
+		},
+		{
+			desc:     "int32",
+			data:     int32(-1),
+			dest:     func() interface{} { return new(int32) },
+			expected: int32(-1),
+		},
+		{
+			desc:     "int64",
+			data:     int64(1 << 40),
+			dest:     func() interface{} { return new(int64) },
+			expected: int64(1 << 40),
+		},
+		{
+			desc:     "bool",
+			data:     true,
+			dest:     func() interface{} { return new(bool) },
+			expected: true,
+		},
+		{
+			desc:     "double",
+			data:     1.5,
+			dest:     func() interface{} { return new(float64) },
+			expected: 1.5,
+		},
+		{
+			desc:     "bytes",
+			data:     []byte("hello"),
+			dest:     func() interface{} { return new([]byte) },
+			expected: []byte("hello"),
+		},
+		{
+			desc:     "attachments",
+			data:     map[interface{}]interface{}{"k1": "v1", "k2": int64(2)},
+			dest:     func() interface{} { return new(map[interface{}]interface{}) },
+			expected: map[interface{}]interface{}{"k1": "v1", "k2": "2"},
+		},
+		{
+			desc:     "exception",
+			data:     errors.New("failed"),
+			dest:     func() interface{} { return new(error) },
+			expected: &Throwable{OriginalClassName: defaultThrowableClassName, OriginalMessage: "failed"},
+		},
+		{
+			desc:     "raw message",
+			data:     wrapperspb.String("hello"),
+			dest:     func() interface{} { return new(interface{}) },
+			expected: RawMessage{0x0a, 0x05, 'h', 'e', 'l', 'l', 'o'},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			e := NewEncoder()
+			assert.Nil(t, e.Encode(test.data))
+			assert.Nil(t, e.Encode("next"))
+
+			d := NewDecoder(e.Buffer())
+			dest := test.dest()
+			assert.Nil(t, d.DecodeTo(dest))
+			switch v := dest.(type) {
+			case *string:
+				assert.Equal(t, test.expected, *v)
+			case *int32:
+				assert.Equal(t, test.expected, *v)
+			case *int64:
+				assert.Equal(t, test.expected, *v)
+			case *bool:
+				assert.Equal(t, test.expected, *v)
+			case *float64:
+				assert.Equal(t, test.expected, *v)
+			case *[]byte:
+				assert.Equal(t, test.expected, *v)
+			case *map[interface{}]interface{}:
+				assert.Equal(t, test.expected, *v)
+			case *error:
+				assert.Equal(t, test.expected, *v)
+			case *interface{}:
+				assert.Equal(t, test.expected, *v)
+			}
+			var next string
+			assert.Nil(t, d.DecodeTo(&next))
+			assert.Equal(t, "next", next)
+			assert.NotNil(t, d.DecodeTo(&next))
+		})
+	}
+
+	_, err := NewDecoder(nil).Decode()
+	assert.Equal(t, errDecodeWithoutType, err)
+}
+
+// TestJavaFixtures decodes the output of GenericProtobufObjectOutput of dubbo-java, which is written to testdata by
+// testdata/ProtobufFixtures.java. The fixtures of values encoded by the go encoder in the same way are compared
+// byte by byte, exceptions are only decoded since dubbo-java writes the stack trace as well.
+func TestJavaFixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		dest     func() interface{}
+		expected interface{}
+		// data is encoded and compared with the fixture if it is not nil
+		data interface{}
+	}{
+		{
+			name:     "string",
+			dest:     func() interface{} { return new(string) },
+			expected: "hello",
+			data:     "hello",
+		},
+		{
+			name:     "int32",
+			dest:     func() interface{} { return new(int32) },
+			expected: int32(-1),
+			data:     int32(-1),
+		},
+		{
+			name:     "int64",
+			dest:     func() interface{} { return new(int64) },
+			expected: int64(1 << 40),
+			data:     int64(1 << 40),
+		},
+		{
+			name:     "bool",
+			dest:     func() interface{} { return new(bool) },
+			expected: true,
+			data:     true,
+		},
+		{
+			name:     "double",
+			dest:     func() interface{} { return new(float64) },
+			expected: 1.5,
+			data:     1.5,
+		},
+		{
+			name:     "bytes",
+			dest:     func() interface{} { return new([]byte) },
+			expected: []byte("hello"),
+			data:     []byte("hello"),
+		},
+		{
+			name:     "attachments",
+			dest:     func() interface{} { return new(map[interface{}]interface{}) },
+			expected: map[interface{}]interface{}{"k1": "v1", "k2": "2"},
+			data:     map[interface{}]interface{}{"k1": "v1", "k2": "2"},
+		},
+		{
+			name:     "exception",
+			dest:     func() interface{} { return new(error) },
+			expected: &Throwable{OriginalClassName: "java.lang.IllegalStateException", OriginalMessage: "illegal"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fixture, err := ioutil.ReadFile(filepath.Join("testdata", test.name+".bin"))
+			if os.IsNotExist(err) {
+				t.Skipf("%s.bin has not been generated by testdata/ProtobufFixtures.java", test.name)
+			}
+			assert.Nil(t, err)
+
+			dest := test.dest()
+			d := NewDecoder(fixture)
+			assert.Nil(t, d.DecodeTo(dest))
+			assert.Equal(t, test.expected, reflect.ValueOf(dest).Elem().Interface())
+			assert.NotNil(t, d.DecodeTo(dest))
+
+			if test.data != nil {
+				e := NewEncoder()
+				assert.Nil(t, e.Encode(test.data))
+				assert.Equal(t, fixture, e.Buffer())
+			}
+		})
+	}
+}
+
+func TestJavaClassName(t *testing.T) {
+	// java_multiple_files = true
+	assert.Equal(t, "com.google.protobuf.StringValue",
+		JavaClassName(wrapperspb.String("").ProtoReflect().Descriptor()))
+	// java_outer_classname = "DescriptorProtos"
+	assert.Equal(t, "com.google.protobuf.DescriptorProtos$FileOptions",
+		JavaClassName(new(descriptorpb.FileOptions).ProtoReflect().Descriptor()))
+	// nested message
+	assert.Equal(t, "com.google.protobuf.DescriptorProtos$DescriptorProto$ExtensionRange",
+		JavaClassName(new(descriptorpb.DescriptorProto_ExtensionRange).ProtoReflect().Descriptor()))
+}
+
+type testArgs struct {
+	Req *wrapperspb.StringValue
+}
+
+func (p *testArgs) GetFirstArgument() interface{} {
+	return p.Req
+}
+
+func TestGetTypes(t *testing.T) {
+	types, err := GetTypes(new(testArgs))
+	assert.Nil(t, err)
+	assert.Equal(t, "Lcom/google/protobuf/StringValue;", types)
+
+	_, err = GetTypes(wrapperspb.String(""))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import java.io.FileOutputStream;
+import java.io.IOException;
+import java.io.OutputStream;
+import java.nio.charset.StandardCharsets;
+import java.util.Map;
+import java.util.TreeMap;
+
+import org.apache.dubbo.common.serialize.protobuf.support.GenericProtobufObjectOutput;
+
+/**
+ * Writes the fixtures read by TestJavaFixtures with the protobuf serialization of dubbo-java, each file holds the
+ * output of a single write of GenericProtobufObjectOutput. Run it in pkg/protobuf with
+ * org.apache.dubbo:dubbo-serialization-protobuf:2.7.23 and its dependencies on the classpath:
+ *
+ *   java -cp "$CLASSPATH" testdata/ProtobufFixtures.java
+ */
+public class ProtobufFixtures {
+
+    interface Writer {
+        void write(GenericProtobufObjectOutput out) throws IOException;
+    }
+
+    public static void main(String[] args) throws IOException {
+        write("string", out -> out.writeUTF("hello"));
+        write("int32", out -> out.writeInt(-1));
+        write("int64", out -> out.writeLong(1L << 40));
+        write("bool", out -> out.writeBool(true));
+        write("double", out -> out.writeDouble(1.5));
+        write("bytes", out -> out.writeBytes("hello".getBytes(StandardCharsets.UTF_8)));
+        // sorted by key, which is the order the go encoder writes the entries in
+        Map<String, Object> attachments = new TreeMap<>();
+        attachments.put("k1", "v1");
+        attachments.put("k2", "2");
+        write("attachments", out -> out.writeAttachments(attachments));
+        write("exception", out -> out.writeThrowable(new IllegalStateException("illegal")));
+    }
+
+    private static void write(String name, Writer writer) throws IOException {
+        try (OutputStream file = new FileOutputStream("testdata/" + name + ".bin")) {
+            GenericProtobufObjectOutput out = new GenericProtobufObjectOutput(file);
+            writer.write(out);
+            out.flushBuffer();
+        }
+    }
+}
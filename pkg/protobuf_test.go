@@ -0,0 +1,266 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/protobuf"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Golden bodies of Greet(com.google.protobuf.StringValue), assembled by hand from the wire format of the dubbo-java
+// protobuf serialization instead of being produced by the codec: every value is a length-delimited message,
+// strings and ints are wrapped by StringValue and Int32Value, attachments are MapValue and exceptions are ThrowableProto.
+// The values are checked against the output of dubbo-java by TestJavaFixtures of the protobuf package.
+var (
+	pbGreetRequestBody = "07" + "0a05" + hexOf("2.0.2") + // dubbo version
+		"2f" + "0a2d" + hexOf(testJavaClassName) + // path
+		"00" + // empty version
+		"07" + "0a05" + hexOf("Greet") + // method name
+		"23" + "0a21" + hexOf("Lcom/google/protobuf/StringValue;") + // parameter types
+		"07" + "0a05" + hexOf("world") + // argument
+		"73" + // attachments sorted by key
+		"0a3a" + "0a09" + hexOf("interface") + "122d" + hexOf(testJavaClassName) +
+		"0a35" + "0a04" + hexOf("path") + "122d" + hexOf(testJavaClassName)
+	pbGreetResponseBody = "02" + "0801" + // RESPONSE_VALUE
+		"0d" + "0a0b" + hexOf("Hello world")
+	pbGreetResponseWithAttachmentsBody = "02" + "0804" + // RESPONSE_VALUE_WITH_ATTACHMENTS
+		"0d" + "0a0b" + hexOf("Hello world") +
+		"0a" + "0a08" + "0a02" + hexOf("k1") + "1202" + hexOf("v1")
+	pbGreetExceptionBody = "00" + // RESPONSE_WITH_EXCEPTION, the default value 0 is omitted
+		"2a" + "0a1f" + hexOf("java.lang.IllegalStateException") + "1207" + hexOf("illegal")
+	pbHeartbeatBody = "03" + "0a01" + hexOf("H") // MOCK_HEARTBEAT_EVENT written for null
+	// errors not specifying java class name are replied as java.lang.Exception
+	pbServerExceptionBody = "00" +
+		"1e" + "0a13" + hexOf("java.lang.Exception") + "1207" + hexOf("illegal")
+)
+
+func hexOf(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
+
+// pbGreetArgs and pbGreetResult are the same as the args and result generated by kitex protobuf.
+type pbGreetArgs struct {
+	Req *wrapperspb.StringValue
+}
+
+func (p *pbGreetArgs) Marshal(out []byte) ([]byte, error) {
+	if p.Req == nil {
+		return out, nil
+	}
+	return proto.Marshal(p.Req)
+}
+
+func (p *pbGreetArgs) Unmarshal(in []byte) error {
+	msg := new(wrapperspb.StringValue)
+	if err := proto.Unmarshal(in, msg); err != nil {
+		return err
+	}
+	p.Req = msg
+	return nil
+}
+
+func (p *pbGreetArgs) GetFirstArgument() interface{} {
+	return p.Req
+}
+
+type pbGreetResult struct {
+	Success *wrapperspb.StringValue
+}
+
+func (p *pbGreetResult) Marshal(out []byte) ([]byte, error) {
+	if p.Success == nil {
+		return out, nil
+	}
+	return proto.Marshal(p.Success)
+}
+
+func (p *pbGreetResult) Unmarshal(in []byte) error {
+	msg := new(wrapperspb.StringValue)
+	if err := proto.Unmarshal(in, msg); err != nil {
+		return err
+	}
+	p.Success = msg
+	return nil
+}
+
+func (p *pbGreetResult) GetResult() interface{} {
+	return p.Success
+}
+
+var pbServiceInfo = &serviceinfo.ServiceInfo{
+	ServiceName: "GreetService",
+	Methods: map[string]serviceinfo.MethodInfo{
+		"Greet": serviceinfo.NewMethodInfo(nil,
+			func() interface{} { return new(pbGreetArgs) },
+			func() interface{} { return new(pbGreetResult) },
+			false),
+	},
+	PayloadCodec: serviceinfo.Protobuf,
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.Nil(t, err)
+	return b
+}
+
+func TestProtobufClient(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithSerialization(protobuf.SerializationName))
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "Greet", nil, nil),
+		rpcinfo.NewInvocation("GreetService", "Greet"), rpcinfo.NewRPCConfig(), nil)
+	sendMsg := remote.NewMessage(&pbGreetArgs{Req: wrapperspb.String("world")}, pbServiceInfo, ri, remote.Call, remote.Client)
+	out := remote.NewReaderWriterBuffer(1024)
+	assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+	buf, err := out.Bytes()
+	assert.Nil(t, err)
+	header := new(dubbo_spec.DubboHeader)
+	assert.Nil(t, header.DecodeFromByteSlice(buf))
+	assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_PROTOBUF), header.SerializationID)
+	assert.Equal(t, pbGreetRequestBody, hex.EncodeToString(buf[dubbo_spec.HEADER_SIZE:]))
+
+	tests := []struct {
+		desc     string
+		body     string
+		expected func(t *testing.T, ctx context.Context, result *pbGreetResult, err error)
+	}{
+		{
+			desc: "response",
+			body: pbGreetResponseBody,
+			expected: func(t *testing.T, ctx context.Context, result *pbGreetResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "Hello world", result.Success.GetValue())
+			},
+		},
+		{
+			desc: "response with attachments",
+			body: pbGreetResponseWithAttachmentsBody,
+			expected: func(t *testing.T, ctx context.Context, result *pbGreetResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "Hello world", result.Success.GetValue())
+				assert.Equal(t, map[string]interface{}{"k1": "v1"}, GetAttachments(ctx))
+			},
+		},
+		{
+			desc: "exception",
+			body: pbGreetExceptionBody,
+			expected: func(t *testing.T, ctx context.Context, result *pbGreetResult, err error) {
+				assert.Equal(t, &protobuf.Throwable{
+					OriginalClassName: "java.lang.IllegalStateException",
+					OriginalMessage:   "illegal",
+				}, err)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			body := mustDecodeHex(t, test.body)
+			respHeader := &dubbo_spec.DubboHeader{
+				SerializationID: dubbo_spec.SERIALIZATION_ID_PROTOBUF,
+				Status:          dubbo_spec.StatusOK,
+				RequestID:       uint64(ri.Invocation().SeqID()),
+				DataLength:      uint32(len(body)),
+			}
+			ctx := WithAttachments(context.Background(), nil)
+			result := new(pbGreetResult)
+			recvMsg := remote.NewMessage(result, pbServiceInfo, ri, remote.Reply, remote.Client)
+			err := codec.Decode(ctx, recvMsg, remote.NewReaderBuffer(append(respHeader.EncodeToByteSlice(), body...)))
+			test.expected(t, ctx, result, err)
+		})
+	}
+}
+
+func TestProtobufServer(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+	body := mustDecodeHex(t, pbGreetRequestBody)
+	header := &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_PROTOBUF,
+		RequestID:       1,
+		DataLength:      uint32(len(body)),
+	}
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+		rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+	recvMsg := remote.NewMessage(nil, pbServiceInfo, ri, remote.Call, remote.Server)
+	assert.Nil(t, codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))))
+	assert.Equal(t, "Greet", ri.Invocation().MethodName())
+	assert.Equal(t, "world", recvMsg.Data().(*pbGreetArgs).Req.GetValue())
+
+	tests := []struct {
+		desc     string
+		msgType  remote.MessageType
+		data     interface{}
+		expected string
+	}{
+		{
+			desc:     "response",
+			msgType:  remote.Reply,
+			data:     &pbGreetResult{Success: wrapperspb.String("Hello world")},
+			expected: pbGreetResponseBody,
+		},
+		{
+			desc:     "exception",
+			msgType:  remote.Exception,
+			data:     errors.New("illegal"),
+			expected: pbServerExceptionBody,
+		},
+		{
+			desc:     "heartbeat",
+			msgType:  remote.Heartbeat,
+			expected: pbHeartbeatBody,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sendMsg := remote.NewMessage(test.data, pbServiceInfo, ri, test.msgType, remote.Server)
+			out := remote.NewReaderWriterBuffer(1024)
+			assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+			buf, err := out.Bytes()
+			assert.Nil(t, err)
+			respHeader := new(dubbo_spec.DubboHeader)
+			assert.Nil(t, respHeader.DecodeFromByteSlice(buf))
+			assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_PROTOBUF), respHeader.SerializationID)
+			assert.Equal(t, test.expected, hex.EncodeToString(buf[dubbo_spec.HEADER_SIZE:]))
+		})
+	}
+
+	// heartbeat request
+	body = mustDecodeHex(t, pbHeartbeatBody)
+	header = &dubbo_spec.DubboHeader{
+		IsRequest:       true,
+		IsEvent:         true,
+		SerializationID: dubbo_spec.SERIALIZATION_ID_PROTOBUF,
+		RequestID:       2,
+		DataLength:      uint32(len(body)),
+	}
+	recvMsg = remote.NewMessage(nil, pbServiceInfo, ri, remote.Call, remote.Server)
+	assert.Nil(t, codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))))
+	assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/cloudwego/kitex/pkg/discovery"
+	"github.com/cloudwego/kitex/pkg/loadbalance"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+// readonlyProviders records addresses of dubbo providers that have sent READONLY_EVENT.
+// Every DubboCodec owns one, so that clients with different codecs do not share the states.
+type readonlyProviders struct {
+	// key: address string, val: struct{}
+	addrs sync.Map
+}
+
+func newReadonlyProviders() *readonlyProviders {
+	return new(readonlyProviders)
+}
+
+// handleEvent processes event data sent by the dubbo provider specified by addr.
+// READONLY_EVENT means the provider is shutting down, new requests should not be sent to it.
+// WRITABLE_EVENT cancels READONLY_EVENT.
+func (r *readonlyProviders) handleEvent(addr net.Addr, event interface{}) {
+	switch event {
+	case dubbo_spec.READONLY_EVENT:
+		r.mark(addr)
+	case dubbo_spec.WRITABLE_EVENT:
+		r.unmark(addr)
+	}
+}
+
+func (r *readonlyProviders) mark(addr net.Addr) {
+	if addr == nil {
+		return
+	}
+	r.addrs.Store(addr.String(), struct{}{})
+}
+
+func (r *readonlyProviders) unmark(addr net.Addr) {
+	if addr == nil {
+		return
+	}
+	r.addrs.Delete(addr.String())
+}
+
+func (r *readonlyProviders) isReadonly(addr net.Addr) bool {
+	if addr == nil {
+		return false
+	}
+	_, ok := r.addrs.Load(addr.String())
+	return ok
+}
+
+var _ loadbalance.Rebalancer = (*readonlyLoadbalancer)(nil)
+
+// Loadbalancer wraps lb so that dubbo providers which have sent READONLY_EVENT (they are shutting down)
+// would not be picked for new requests. If all the providers are readonly, the Instance picked by lb is used.
+// Providers would be writable again when receiving WRITABLE_EVENT or being added by service discovery.
+// The events are received on the connections of the client using this codec, wrap the connection pool of the
+// client with ConnPool so that events received on idle connections are processed when they arrive.
+func (m *DubboCodec) Loadbalancer(lb loadbalance.Loadbalancer) loadbalance.Loadbalancer {
+	if lb == nil {
+		lb = loadbalance.NewWeightedBalancer()
+	}
+	return &readonlyLoadbalancer{lb: lb, providers: m.readonly}
+}
+
+type readonlyLoadbalancer struct {
+	lb        loadbalance.Loadbalancer
+	providers *readonlyProviders
+}
+
+func (r *readonlyLoadbalancer) GetPicker(result discovery.Result) loadbalance.Picker {
+	maxAttempts := len(result.Instances)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &readonlyPicker{
+		picker:      r.lb.GetPicker(result),
+		providers:   r.providers,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (r *readonlyLoadbalancer) Name() string {
+	return "dubbo_readonly_" + r.lb.Name()
+}
+
+func (r *readonlyLoadbalancer) Rebalance(change discovery.Change) {
+	// providers added by service discovery are restarted ones
+	for _, ins := range change.Added {
+		r.providers.unmark(ins.Address())
+	}
+	if rebalancer, ok := r.lb.(loadbalance.Rebalancer); ok {
+		rebalancer.Rebalance(change)
+	}
+}
+
+func (r *readonlyLoadbalancer) Delete(change discovery.Change) {
+	if rebalancer, ok := r.lb.(loadbalance.Rebalancer); ok {
+		rebalancer.Delete(change)
+	}
+}
+
+type readonlyPicker struct {
+	picker      loadbalance.Picker
+	providers   *readonlyProviders
+	maxAttempts int
+}
+
+func (p *readonlyPicker) Next(ctx context.Context, request interface{}) (ins discovery.Instance) {
+	for i := 0; i < p.maxAttempts; i++ {
+		ins = p.picker.Next(ctx, request)
+		if ins == nil {
+			return nil
+		}
+		if !p.providers.isReadonly(ins.Address()) {
+			return ins
+		}
+	}
+	return ins
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/discovery"
+	"github.com/cloudwego/kitex/pkg/loadbalance"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/netpoll"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundRobinBalancer struct{}
+
+func (b *roundRobinBalancer) GetPicker(result discovery.Result) loadbalance.Picker {
+	return &roundRobinPicker{instances: result.Instances}
+}
+
+func (b *roundRobinBalancer) Name() string {
+	return "round_robin"
+}
+
+type roundRobinPicker struct {
+	instances []discovery.Instance
+	idx       int
+}
+
+func (p *roundRobinPicker) Next(ctx context.Context, request interface{}) discovery.Instance {
+	if len(p.instances) == 0 {
+		return nil
+	}
+	ins := p.instances[p.idx%len(p.instances)]
+	p.idx++
+	return ins
+}
+
+func TestReadonlyLoadbalancer(t *testing.T) {
+	insA := discovery.NewInstance("tcp", "127.0.0.1:20001", 10, nil)
+	insB := discovery.NewInstance("tcp", "127.0.0.1:20002", 10, nil)
+	result := discovery.Result{Instances: []discovery.Instance{insA, insB}}
+	codec := NewDubboCodec(WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"))
+	lb := codec.Loadbalancer(new(roundRobinBalancer))
+	providers := codec.readonly
+
+	providers.mark(insA.Address())
+	picker := lb.GetPicker(result)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, insB, picker.Next(context.Background(), nil))
+	}
+
+	// all the providers are readonly
+	providers.mark(insB.Address())
+	assert.NotNil(t, lb.GetPicker(result).Next(context.Background(), nil))
+
+	// provider restarted
+	lb.(loadbalance.Rebalancer).Rebalance(discovery.Change{Added: []discovery.Instance{insA}})
+	picker = lb.GetPicker(result)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, insA, picker.Next(context.Background(), nil))
+	}
+
+	// WRITABLE_EVENT received
+	providers.unmark(insB.Address())
+	assert.False(t, providers.isReadonly(insB.Address()))
+
+	// states are not shared by codecs
+	providers.mark(insA.Address())
+	assert.False(t, NewDubboCodec(WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider")).readonly.isReadonly(insA.Address()))
+}
+
+// bufferedTestConn is a netpoll-like connection whose input data has been buffered.
+type bufferedTestConn struct {
+	net.Conn
+	addr   net.Addr
+	input  *netpoll.LinkBuffer
+	output bytes.Buffer
+	closed bool
+}
+
+func (c *bufferedTestConn) Reader() netpoll.Reader {
+	return c.input
+}
+
+func (c *bufferedTestConn) Read(b []byte) (int, error) {
+	buf, err := c.input.Next(len(b))
+	return copy(b, buf), err
+}
+
+func (c *bufferedTestConn) Write(b []byte) (int, error) {
+	return c.output.Write(b)
+}
+
+func (c *bufferedTestConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func (c *bufferedTestConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type testConnPool struct {
+	remote.ConnPool
+	conn net.Conn
+}
+
+func (p *testConnPool) Get(ctx context.Context, network, address string, opt remote.ConnOption) (net.Conn, error) {
+	return p.conn, nil
+}
+
+func (p *testConnPool) Put(conn net.Conn) error {
+	return nil
+}
+
+func (p *testConnPool) Close() error {
+	return nil
+}
+
+func TestReadonlyIdleConnection(t *testing.T) {
+	insA := discovery.NewInstance("tcp", "127.0.0.1:20001", 10, nil)
+	insB := discovery.NewInstance("tcp", "127.0.0.1:20002", 10, nil)
+	result := discovery.Result{Instances: []discovery.Instance{insA, insB}}
+	codec := NewDubboCodec(WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"))
+	lb := codec.Loadbalancer(new(roundRobinBalancer))
+
+	conn := &bufferedTestConn{addr: insA.Address(), input: netpoll.NewLinkBuffer()}
+	pool := codec.ConnPool(&testConnPool{conn: conn})
+	c, err := pool.Get(context.Background(), "tcp", insA.Address().String(), remote.ConnOption{})
+	assert.Nil(t, err)
+	assert.Nil(t, pool.Put(c))
+	picker := lb.GetPicker(result)
+	assert.Equal(t, insA, picker.Next(context.Background(), nil))
+
+	// stale response, heartbeat request and READONLY_EVENT received on the idle connection,
+	// followed by a package which has not been received entirely
+	resp, _ := buildHeartbeatResponse(1)
+	heartbeat, heartbeatID, _ := buildEventRequest(nil, true)
+	event, _, _ := buildEventRequest(dubbo_spec.READONLY_EVENT, false)
+	writable, _, _ := buildEventRequest(dubbo_spec.WRITABLE_EVENT, false)
+	for _, pkg := range [][]byte{resp, heartbeat, event, writable[:dubbo_spec.HEADER_SIZE+1]} {
+		conn.input.WriteBinary(pkg)
+	}
+	assert.Nil(t, conn.input.Flush())
+	// picking does not process the events
+	assert.False(t, codec.readonly.isReadonly(insA.Address()))
+	pool.(*heartbeatPool).checkIdleConns()
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, insB, picker.Next(context.Background(), nil))
+	}
+	assert.True(t, codec.readonly.isReadonly(insA.Address()))
+	// heartbeat request is replied
+	header := new(dubbo_spec.DubboHeader)
+	assert.Nil(t, header.DecodeFromByteSlice(conn.output.Bytes()[:dubbo_spec.HEADER_SIZE]))
+	assert.Equal(t, heartbeatID, header.RequestID)
+	assert.True(t, header.IsEvent)
+	assert.False(t, header.IsRequest)
+	// the incomplete package is left in the buffer
+	assert.Equal(t, dubbo_spec.HEADER_SIZE+1, conn.input.Len())
+
+	// events received after the rpc call are processed when the connection is put back
+	c, err = pool.Get(context.Background(), "tcp", insA.Address().String(), remote.ConnOption{})
+	assert.Nil(t, err)
+	conn.input.WriteBinary(writable[dubbo_spec.HEADER_SIZE+1:])
+	assert.Nil(t, conn.input.Flush())
+	assert.Nil(t, pool.Put(c))
+	assert.False(t, codec.readonly.isReadonly(insA.Address()))
+	assert.Zero(t, conn.input.Len())
+
+	assert.Nil(t, pool.Close())
+}
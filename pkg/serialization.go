@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+)
+
+// serializationExtraKey is the key of Invocation extra storing the serialization of the request on the server side.
+const serializationExtraKey = "dubbo_serialization"
+
+// UnsupportedSerializationError is returned when the SerializationID of a dubbo package is not supported,
+// e.g. the peer is configured with kryo or fst. The body of the package is discarded without being decoded.
+// On the client side, use errors.As to extract it:
+//
+//	var serializationErr *dubbo.UnsupportedSerializationError
+//	if errors.As(err, &serializationErr) {
+//		// process serializationErr.SerializationID
+//	}
+type UnsupportedSerializationError struct {
+	SerializationID uint8
+}
+
+func (e *UnsupportedSerializationError) Error() string {
+	return fmt.Sprintf("unsupported serialization: %s(SerializationID: %d)",
+		dubbo_spec.GetSerializationName(e.SerializationID), e.SerializationID)
+}
+
+// decodeSerialization returns the serialization specified by the SerializationID of header.
+// On the server side, it is recorded so that the response could be encoded with the same serialization,
+// which is required by dubbo consumers.
+func (m *DubboCodec) decodeSerialization(header *dubbo_spec.DubboHeader, message remote.Message) (iface.Serialization, error) {
+	serialization, ok := dubbo_spec.GetSerialization(header.SerializationID)
+	if !ok {
+		return nil, &UnsupportedSerializationError{SerializationID: header.SerializationID}
+	}
+	if message.RPCRole() == remote.Server {
+		setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+		if !ok {
+			return nil, errors.New("the interface Invocation doesn't implement InvocationSetter")
+		}
+		setter.SetExtra(serializationExtraKey, serialization)
+	}
+	return serialization, nil
+}
+
+// getSerialization returns the serialization used to encode message.
+func (m *DubboCodec) getSerialization(message remote.Message) iface.Serialization {
+	if message.RPCRole() == remote.Server {
+		if serialization, ok := message.RPCInfo().Invocation().Extra(serializationExtraKey).(iface.Serialization); ok {
+			return serialization
+		}
+	}
+	return m.opt.Serialization
+}
+
+// rejectSerialization discards the body of the package whose serialization is not supported.
+// On the server side, the request is rejected by rejectInvocation without closing the connection, it is replied with
+// StatusBadRequest and the error message encoded with the serialization of DubboCodec, since the one of the request
+// is unavailable.
+func rejectSerialization(header *dubbo_spec.DubboHeader, message remote.Message, in remote.ByteBuffer, err error) error {
+	if message.RPCRole() == remote.Server && header.IsRequest {
+		return rejectRequest(header, message, in, err)
+	}
+	if skipErr := in.Skip(int(header.DataLength)); skipErr != nil {
+		return skipErr
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerSerialization(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+	tests := []struct {
+		desc            string
+		serializationID uint8
+		body            string
+		expected        string
+	}{
+		{
+			desc:            "fastjson",
+			serializationID: dubbo_spec.SERIALIZATION_ID_FASTJSON,
+			body: `"2.0.2"
+"` + testJavaClassName + `"
+""
+"$echo"
+"Ljava/lang/Object;"
+"OK"
+{"path":"` + testJavaClassName + `"}
+`,
+			expected: "1\n\"OK\"\n",
+		},
+		{
+			desc:            "gson",
+			serializationID: dubbo_spec.SERIALIZATION_ID_GSON,
+			body: `"2.0.2"
+"` + testJavaClassName + `"
+""
+"$echo"
+"Ljava/lang/Object;"
+{"key":[1,2]}
+{}
+`,
+			expected: "1\n{\"key\":[1,2]}\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			header := &dubbo_spec.DubboHeader{
+				IsRequest:       true,
+				SerializationID: test.serializationID,
+				RequestID:       1,
+				DataLength:      uint32(len(test.body)),
+			}
+			ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+				rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+			svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+			recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+			in := remote.NewReaderBuffer(append(header.EncodeToByteSlice(), test.body...))
+			assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+			assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+
+			// reply with the serialization of the request
+			sendMsg := remote.NewMessage(nil, svcInfo, ri, remote.Heartbeat, remote.Server)
+			out := remote.NewReaderWriterBuffer(1024)
+			assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+			buf, err := out.Bytes()
+			assert.Nil(t, err)
+			respHeader := new(dubbo_spec.DubboHeader)
+			assert.Nil(t, respHeader.DecodeFromByteSlice(buf))
+			assert.Equal(t, test.serializationID, respHeader.SerializationID)
+			assert.Equal(t, test.expected, string(buf[dubbo_spec.HEADER_SIZE:]))
+		})
+	}
+}
+
+func TestClientSerialization(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithSerialization("fastjson"))
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", GenericInvokeMethod, nil, nil),
+		rpcinfo.NewInvocation(GenericServiceName, GenericInvokeMethod), rpcinfo.NewRPCConfig(), nil)
+	sendMsg := remote.NewMessage(&GenericInvokeArgs{
+		Method:         "Greet",
+		ParameterTypes: []string{"java.lang.String"},
+		Args:           []interface{}{"world"},
+	}, genericServiceInfo, ri, remote.Call, remote.Client)
+	out := remote.NewReaderWriterBuffer(1024)
+	assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+	buf, err := out.Bytes()
+	assert.Nil(t, err)
+	header := new(dubbo_spec.DubboHeader)
+	assert.Nil(t, header.DecodeFromByteSlice(buf))
+	assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_FASTJSON), header.SerializationID)
+	assert.Equal(t, `"2.0.2"
+"`+testJavaClassName+`"
+""
+"$invoke"
+"Ljava/lang/String;[Ljava/lang/String;[Ljava/lang/Object;"
+"Greet"
+["java.lang.String"]
+["world"]
+{"generic":"true","interface":"`+testJavaClassName+`","path":"`+testJavaClassName+`"}
+`, string(buf[dubbo_spec.HEADER_SIZE:]))
+
+	body := "4\n{\"name\":\"world\"}\n{\"k1\":1}\n"
+	respHeader := &dubbo_spec.DubboHeader{
+		SerializationID: dubbo_spec.SERIALIZATION_ID_FASTJSON,
+		Status:          dubbo_spec.StatusOK,
+		RequestID:       uint64(ri.Invocation().SeqID()),
+		DataLength:      uint32(len(body)),
+	}
+	ctx := WithAttachments(context.Background(), nil)
+	recvMsg := remote.NewMessage(new(GenericInvokeResult), genericServiceInfo, ri, remote.Reply, remote.Client)
+	assert.Nil(t, codec.Decode(ctx, recvMsg, remote.NewReaderBuffer(append(respHeader.EncodeToByteSlice(), body...))))
+	assert.Equal(t, map[string]interface{}{"name": "world"}, recvMsg.Data().(*GenericInvokeResult).Success)
+	assert.Equal(t, map[string]interface{}{"k1": int32(1)}, GetAttachments(ctx))
+}
+
+func TestUnsupportedSerialization(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName))
+	body := "undecodable kryo body"
+
+	t.Run("server", func(t *testing.T) {
+		header := &dubbo_spec.DubboHeader{
+			IsRequest:       true,
+			SerializationID: 8,
+			RequestID:       7,
+			DataLength:      uint32(len(body)),
+		}
+		ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+			rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+		svcInfo := &serviceinfo.ServiceInfo{ServiceName: "GreetService"}
+		recvMsg := remote.NewMessage(nil, svcInfo, ri, remote.Call, remote.Server)
+		in := remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))
+		// the request is rejected without closing the connection
+		assert.Nil(t, codec.Decode(context.Background(), recvMsg, in))
+		assert.Equal(t, remote.Heartbeat, recvMsg.MessageType())
+		rejection, ok := getRejection(recvMsg)
+		assert.True(t, ok)
+		var transErr *remote.TransError
+		assert.True(t, errors.As(rejection, &transErr))
+		assert.Equal(t, int32(remote.ProtocolError), transErr.TypeID())
+		var serializationErr *UnsupportedSerializationError
+		assert.True(t, errors.As(rejection, &serializationErr))
+		assert.Equal(t, uint8(8), serializationErr.SerializationID)
+		assert.Equal(t, 0, in.ReadableLen())
+		assert.Equal(t, int32(7), ri.Invocation().SeqID())
+
+		// reply with StatusBadRequest and hessian2
+		sendMsg := remote.NewMessage(nil, svcInfo, ri, remote.Heartbeat, remote.Server)
+		out := remote.NewReaderWriterBuffer(1024)
+		assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+		buf, err := out.Bytes()
+		assert.Nil(t, err)
+		respHeader := new(dubbo_spec.DubboHeader)
+		assert.Nil(t, respHeader.DecodeFromByteSlice(buf))
+		assert.Equal(t, dubbo_spec.StatusBadRequest, respHeader.Status)
+		assert.Equal(t, uint8(dubbo_spec.SERIALIZATION_ID_HESSIAN), respHeader.SerializationID)
+		assert.Equal(t, uint64(7), respHeader.RequestID)
+		msg, err := hessian2.NewDecoder(buf[dubbo_spec.HEADER_SIZE:]).Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, "unsupported serialization: kryo(SerializationID: 8)", msg)
+	})
+
+	t.Run("client", func(t *testing.T) {
+		ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", GenericInvokeMethod, nil, nil),
+			rpcinfo.NewInvocation(GenericServiceName, GenericInvokeMethod), rpcinfo.NewRPCConfig(), nil)
+		header := &dubbo_spec.DubboHeader{
+			SerializationID: 9,
+			Status:          dubbo_spec.StatusOK,
+			RequestID:       uint64(ri.Invocation().SeqID()),
+			DataLength:      uint32(len(body)),
+		}
+		recvMsg := remote.NewMessage(new(GenericInvokeResult), genericServiceInfo, ri, remote.Reply, remote.Client)
+		in := remote.NewReaderBuffer(append(header.EncodeToByteSlice(), body...))
+		err := codec.Decode(context.Background(), recvMsg, in)
+		var serializationErr *UnsupportedSerializationError
+		assert.True(t, errors.As(err, &serializationErr))
+		assert.Equal(t, uint8(9), serializationErr.SerializationID)
+		assert.Equal(t, "unsupported serialization: fst(SerializationID: 9)", err.Error())
+		assert.Equal(t, 0, in.ReadableLen())
+	})
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	hessian2_exception "github.com/kitex-contrib/codec-dubbo/pkg/hessian2/exception"
+)
+
+// rejectionExtraKey is the key of Invocation extra storing the error of the request rejected by rejectInvocation.
+const rejectionExtraKey = "dubbo_rejection"
+
+// getStatusCode refers to the exception processing logic of dubbo-java, it determines whether the err
+// returned by server is in the outer layer and classifies it into dubbo status.
+//   - java exceptions and errors returned by business logic are in the inner layer, StatusOK is returned
+//     and the err would be encoded as an exception in the payload.
+//   - errors produced by kitex before or after the business logic are in the outer layer, the corresponding
+//     status is returned and the err would be encoded as an error message.
+func getStatusCode(err error) dubbo_spec.StatusCode {
+	if err == nil {
+		return dubbo_spec.StatusOK
+	}
+	if _, ok := hessian2_exception.FromError(err); ok {
+		return dubbo_spec.StatusOK
+	}
+	if errors.Is(err, kerrors.ErrOverlimit) {
+		return dubbo_spec.StatusServerPoolExhausted
+	}
+	if kerrors.IsTimeoutError(err) || errors.Is(err, context.DeadlineExceeded) {
+		return dubbo_spec.StatusServerTimeout
+	}
+	var transErr *remote.TransError
+	if errors.As(err, &transErr) {
+		switch transErr.TypeID() {
+		case remote.UnknownMethod, remote.UnknownService:
+			return dubbo_spec.StatusServiceNotFound
+		case remote.InvalidMessageTypeException, remote.WrongMethodName, remote.BadSequenceID, remote.ProtocolError:
+			return dubbo_spec.StatusBadRequest
+		}
+	}
+	return dubbo_spec.StatusOK
+}
+
+// wrapDecodeError converts err produced when decoding request to TransError, so that it is classified by getStatusCode.
+func wrapDecodeError(err error, typeID int32) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*remote.TransError); ok {
+		return err
+	}
+	return remote.NewTransError(typeID, err)
+}
+
+// rejectInvocation records err of a request which could not be served, e.g. the requested service or method does not
+// exist or the body could not be decoded, and marks the message as Heartbeat.
+// Decode errors make kitex server close the connection, which fails all the other requests multiplexed on it by
+// dubbo consumers. Like $echo, kitex server replies Heartbeat with DubboCodec.Encode directly instead, so err is
+// replied with the dubbo status of the TransError of typeID without invoking the handler.
+func rejectInvocation(message remote.Message, err error, typeID int32) error {
+	setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return errors.New("the interface Invocation doesn't implement InvocationSetter")
+	}
+	setter.SetExtra(rejectionExtraKey, wrapDecodeError(err, typeID))
+	message.SetMessageType(remote.Heartbeat)
+	return nil
+}
+
+// getRejection returns the error recorded by rejectInvocation.
+func getRejection(message remote.Message) (error, bool) {
+	err, ok := message.RPCInfo().Invocation().Extra(rejectionExtraKey).(error)
+	return err, ok
+}
+
+// getRejectionStatus returns the dubbo status replying the error recorded by rejectInvocation.
+func getRejectionStatus(err error) dubbo_spec.StatusCode {
+	if status := getStatusCode(err); status != dubbo_spec.StatusOK {
+		return status
+	}
+	return dubbo_spec.StatusBadRequest
+}
+
+// DubboStatusError is returned by client when the status of dubbo response is not StatusOK, which means the
+// request failed in the outer layer of the remoting service. (eg. non-exist InterfaceName)
+// Use errors.As to extract it:
+//
+//	var statusErr *dubbo.DubboStatusError
+//	if errors.As(err, &statusErr) {
+//		// process statusErr.Status
+//	}
+type DubboStatusError struct {
+	Status  dubbo_spec.StatusCode
+	Message string
+}
+
+func (e *DubboStatusError) Error() string {
+	return fmt.Sprintf("dubbo side exception, status: %s, message: %s", e.Status, e.Message)
+}
+
+// newStatusError creates DubboStatusError and wraps it with the kitex error of the same meaning so that
+// retry and circuit breaker of kitex could recognize it:
+//   - StatusClientTimeout and StatusServerTimeout are wrapped with kerrors.ErrRPCTimeout.
+//   - StatusServerPoolExhausted is wrapped with kerrors.ErrOverlimit.
+func newStatusError(status dubbo_spec.StatusCode, msg string) error {
+	statusErr := &DubboStatusError{
+		Status:  status,
+		Message: msg,
+	}
+	switch status {
+	case dubbo_spec.StatusClientTimeout, dubbo_spec.StatusServerTimeout:
+		return kerrors.ErrRPCTimeout.WithCause(statusErr)
+	case dubbo_spec.StatusServerPoolExhausted:
+		return kerrors.ErrOverlimit.WithCause(statusErr)
+	}
+	return statusErr
+}
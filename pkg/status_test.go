@@ -0,0 +1,311 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/cloudwego/kitex/server"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	hessian2_exception "github.com/kitex-contrib/codec-dubbo/pkg/hessian2/exception"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStatusCode(t *testing.T) {
+	tests := []struct {
+		desc     string
+		err      error
+		expected dubbo_spec.StatusCode
+	}{
+		{
+			desc:     "nil err",
+			err:      nil,
+			expected: dubbo_spec.StatusOK,
+		},
+		{
+			desc:     "java exception returned by business logic",
+			err:      remote.NewTransError(remote.InternalError, kerrors.ErrBiz.WithCause(hessian2_exception.NewException("biz"))),
+			expected: dubbo_spec.StatusOK,
+		},
+		{
+			desc:     "error returned by business logic",
+			err:      remote.NewTransError(remote.InternalError, kerrors.ErrBiz.WithCause(errors.New("biz"))),
+			expected: dubbo_spec.StatusOK,
+		},
+		{
+			desc:     "unknown method",
+			err:      remote.NewTransErrorWithMsg(remote.UnknownMethod, "unknown method"),
+			expected: dubbo_spec.StatusServiceNotFound,
+		},
+		{
+			desc:     "unknown service",
+			err:      remote.NewTransErrorWithMsg(remote.UnknownService, "unknown service"),
+			expected: dubbo_spec.StatusServiceNotFound,
+		},
+		{
+			desc:     "decode failure",
+			err:      wrapDecodeError(errors.New("decode failed"), remote.ProtocolError),
+			expected: dubbo_spec.StatusBadRequest,
+		},
+		{
+			desc:     "handler timeout",
+			err:      remote.NewTransError(remote.InternalError, kerrors.ErrRPCTimeout.WithCause(errors.New("timeout"))),
+			expected: dubbo_spec.StatusServerTimeout,
+		},
+		{
+			desc:     "overload",
+			err:      remote.NewTransError(remote.InternalError, kerrors.ErrOverlimit.WithCause(errors.New("too many requests"))),
+			expected: dubbo_spec.StatusServerPoolExhausted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, getStatusCode(test.err))
+		})
+	}
+}
+
+func TestNewStatusError(t *testing.T) {
+	tests := []struct {
+		desc     string
+		status   dubbo_spec.StatusCode
+		expected func(t *testing.T, err error)
+	}{
+		{
+			desc:   "service not found",
+			status: dubbo_spec.StatusServiceNotFound,
+			expected: func(t *testing.T, err error) {
+				var statusErr *DubboStatusError
+				assert.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, dubbo_spec.StatusServiceNotFound, statusErr.Status)
+				assert.Equal(t, "test message", statusErr.Message)
+				assert.False(t, kerrors.IsTimeoutError(err))
+			},
+		},
+		{
+			desc:   "server timeout",
+			status: dubbo_spec.StatusServerTimeout,
+			expected: func(t *testing.T, err error) {
+				var statusErr *DubboStatusError
+				assert.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, dubbo_spec.StatusServerTimeout, statusErr.Status)
+				assert.True(t, kerrors.IsTimeoutError(err))
+			},
+		},
+		{
+			desc:   "client timeout",
+			status: dubbo_spec.StatusClientTimeout,
+			expected: func(t *testing.T, err error) {
+				assert.True(t, kerrors.IsTimeoutError(err))
+			},
+		},
+		{
+			desc:   "server pool exhausted",
+			status: dubbo_spec.StatusServerPoolExhausted,
+			expected: func(t *testing.T, err error) {
+				var statusErr *DubboStatusError
+				assert.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, dubbo_spec.StatusServerPoolExhausted, statusErr.Status)
+				assert.True(t, errors.Is(err, kerrors.ErrOverlimit))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			test.expected(t, newStatusError(test.status, "test message"))
+		})
+	}
+}
+
+// statusTestArgs is the same as the args generated by kitex for Greet(req string).
+type statusTestArgs struct {
+	Req string
+}
+
+func (p *statusTestArgs) Encode(e iface.Encoder) error {
+	return e.Encode(p.Req)
+}
+
+func (p *statusTestArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Req)
+}
+
+// runStatusTestServer runs a dubbo server of GreetService and returns the connection to it.
+func runStatusTestServer(t *testing.T, codec *DubboCodec) net.Conn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	assert.Nil(t, ln.Close())
+
+	svr := server.NewServer(server.WithServiceAddr(addr), server.WithCodec(codec), server.WithExitWaitTime(time.Millisecond))
+	assert.Nil(t, svr.RegisterService(&serviceinfo.ServiceInfo{
+		ServiceName: "GreetService",
+		Methods: map[string]serviceinfo.MethodInfo{
+			"Greet": serviceinfo.NewMethodInfo(nil,
+				func() interface{} { return new(statusTestArgs) },
+				func() interface{} { return new(statusTestArgs) },
+				false,
+			),
+		},
+	}, new(struct{})))
+	go svr.Run()
+	t.Cleanup(func() {
+		svr.Stop()
+	})
+
+	// retry until the server is listening
+	for i := 0; i < 50; i++ {
+		var conn net.Conn
+		if conn, err = net.Dial("tcp", addr.String()); err == nil {
+			t.Cleanup(func() {
+				conn.Close()
+			})
+			return conn
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal(err)
+	return nil
+}
+
+// writeStatusTestRequest writes the dubbo package of the request whose body is made up of values encoded by hessian2.
+// The SerializationID of the header is hessian2 if it is not specified.
+func writeStatusTestRequest(t *testing.T, conn net.Conn, header dubbo_spec.DubboHeader, values ...interface{}) {
+	var body []byte
+	for _, v := range values {
+		if b, ok := v.([]byte); ok {
+			// raw bytes are written as they are
+			body = append(body, b...)
+			continue
+		}
+		encoder := hessian2.NewEncoder()
+		assert.Nil(t, encoder.Encode(v))
+		body = append(body, encoder.Buffer()...)
+	}
+	header.IsRequest = true
+	if header.SerializationID == 0 {
+		header.SerializationID = dubbo_spec.SERIALIZATION_ID_HESSIAN
+	}
+	header.DataLength = uint32(len(body))
+	_, err := conn.Write(append(header.EncodeToByteSlice(), body...))
+	assert.Nil(t, err)
+}
+
+// readStatusTestResponse reads the next dubbo package replied by the server.
+func readStatusTestResponse(t *testing.T, conn net.Conn) (*dubbo_spec.DubboHeader, []byte) {
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	buf := make([]byte, dubbo_spec.HEADER_SIZE)
+	_, err := io.ReadFull(conn, buf)
+	assert.Nil(t, err)
+	header := new(dubbo_spec.DubboHeader)
+	assert.Nil(t, header.DecodeFromByteSlice(buf))
+	body := make([]byte, header.DataLength)
+	_, err = io.ReadFull(conn, body)
+	assert.Nil(t, err)
+	return header, body
+}
+
+func TestRejectedRequestKeepsConnection(t *testing.T) {
+	conn := runStatusTestServer(t, NewDubboCodec(WithJavaClassName(testJavaClassName), WithMaxPayload(1024)))
+	attachments := map[interface{}]interface{}{}
+	tests := []struct {
+		desc            string
+		serializationID uint8
+		values          []interface{}
+		expected        dubbo_spec.StatusCode
+	}{
+		{
+			desc: "unknown service",
+			values: []interface{}{dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, "org.cloudwego.kitex.samples.api.UnknownProvider",
+				"", "Greet", "Ljava/lang/String;", "world", attachments},
+			expected: dubbo_spec.StatusServiceNotFound,
+		},
+		{
+			desc: "unknown method",
+			values: []interface{}{dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName,
+				"", "Unknown", "Ljava/lang/String;", "world", attachments},
+			expected: dubbo_spec.StatusServiceNotFound,
+		},
+		{
+			desc: "truncated argument",
+			values: []interface{}{dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName,
+				"", "Greet", "Ljava/lang/String;", []byte{0x05, 'w'}},
+			expected: dubbo_spec.StatusBadRequest,
+		},
+		{
+			desc: "mismatched argument",
+			values: []interface{}{dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName,
+				"", "Greet", "Ljava/lang/String;", int64(1), attachments},
+			expected: dubbo_spec.StatusBadRequest,
+		},
+		{
+			desc:            "unsupported serialization",
+			serializationID: 8,
+			values:          []interface{}{[]byte("undecodable kryo body")},
+			expected:        dubbo_spec.StatusBadRequest,
+		},
+		{
+			desc: "exceeding max payload",
+			values: []interface{}{dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName,
+				"", "Greet", "Ljava/lang/String;", strings.Repeat("a", 2048), attachments},
+			expected: dubbo_spec.StatusBadRequest,
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			requestID := uint64(2*i + 1)
+			writeStatusTestRequest(t, conn, dubbo_spec.DubboHeader{RequestID: requestID, SerializationID: test.serializationID},
+				test.values...)
+			header, _ := readStatusTestResponse(t, conn)
+			assert.Equal(t, requestID, header.RequestID)
+			assert.Equal(t, test.expected, header.Status)
+
+			// the connection is still served
+			writeStatusTestRequest(t, conn, dubbo_spec.DubboHeader{RequestID: requestID + 1}, dubbo_spec.DEFAULT_DUBBO_PROTOCOL_VERSION, testJavaClassName,
+				"", EchoMethod, echoTypes, "hello", attachments)
+			header, body := readStatusTestResponse(t, conn)
+			assert.Equal(t, requestID+1, header.RequestID)
+			assert.Equal(t, dubbo_spec.StatusOK, header.Status)
+			decoder := hessian2.NewDecoder(body)
+			payloadType, err := decoder.Decode()
+			assert.Nil(t, err)
+			assert.Equal(t, int32(dubbo_spec.RESPONSE_VALUE), payloadType)
+			echo, err := decoder.Decode()
+			assert.Nil(t, err)
+			assert.Equal(t, "hello", echo)
+		})
+	}
+}
@@ -0,0 +1,594 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/remote/codec"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/pkg/json"
+)
+
+const (
+	telnetPrompt = "dubbo>"
+	telnetHelp   = ` help                           - Show help.
+ ls [-l] [service]              - List services and methods.
+ invoke [service.]method(args)  - Invoke the service method, args are in JSON.
+ count service [method]         - Count the service invocations.
+ status [-l]                    - Show status.
+ online [service]               - Register the services, all the services by default.
+ offline [service]              - Deregister the services, all the services by default.
+ exit                           - Exit the telnet.`
+
+	// telnetExtraKey is the key of Invocation extra storing the telnet command being processed.
+	telnetExtraKey = "dubbo_telnet"
+	// invocationExtraKey is the key of Invocation extra storing the invocation being counted.
+	invocationExtraKey = "dubbo_invocation"
+)
+
+var errTelnetExit = errors.New("dubbo telnet exit")
+
+// telnetCommand is a telnet command being processed.
+type telnetCommand struct {
+	// reply is the reply of the commands replied by DubboCodec directly.
+	reply string
+	// method is the name of the method invoked by the invoke command, which is replied after the handler returns.
+	method string
+}
+
+// isTelnetCommand reports whether b could be the first byte of a telnet command,
+// which is never the first byte of a dubbo package.
+func isTelnetCommand(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b == '\r' || b == '\n'
+}
+
+// decodeTelnetCommand reads a line of telnet command sent by SREs with `telnet host port`, e.g. ls, invoke, count.
+// Like $echo, commands are replied by DubboCodec.Encode directly as Heartbeat, except for invoke which is
+// dispatched to the handler as a normal request.
+func (m *DubboCodec) decodeTelnetCommand(ctx context.Context, message remote.Message, in remote.ByteBuffer) error {
+	line, err := m.readTelnetLine(in)
+	if err != nil {
+		return err
+	}
+	setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return errors.New("the interface Invocation doesn't implement InvocationSetter")
+	}
+	cmd := new(telnetCommand)
+	setter.SetExtra(telnetExtraKey, cmd)
+
+	name, args := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, args = line[:i], strings.TrimSpace(line[i+1:])
+	}
+	switch name {
+	case "":
+	case "help":
+		cmd.reply = telnetHelp
+	case "ls":
+		cmd.reply = m.telnetList(message, strings.Fields(args))
+	case "invoke":
+		if cmd.method, err = m.decodeTelnetInvoke(message, args); err == nil {
+			return nil
+		}
+		cmd.reply = err.Error()
+	case "count":
+		cmd.reply = m.telnetCount(message, strings.Fields(args))
+	case "status":
+		cmd.reply = m.telnetStatus(strings.Fields(args))
+	case "online", "offline":
+		cmd.reply = m.telnetSetOnline(args, name == "online")
+	case "exit", "quit":
+		return errTelnetExit
+	default:
+		cmd.reply = fmt.Sprintf("Unsupported command: %s", name)
+	}
+	message.SetMessageType(remote.Heartbeat)
+	return nil
+}
+
+// readTelnetLine reads the next line and trims the spaces. The line is limited by the max payload.
+func (m *DubboCodec) readTelnetLine(in remote.ByteBuffer) (string, error) {
+	var scanned int
+	for {
+		n := in.ReadableLen()
+		if n <= scanned {
+			n = scanned + 1
+		}
+		buf, err := in.Peek(n)
+		if err != nil {
+			return "", err
+		}
+		if i := bytes.IndexByte(buf[scanned:], '\n'); i >= 0 {
+			n = scanned + i + 1
+		}
+		if err := checkPayload(n, m.opt.MaxPayload); err != nil {
+			return "", err
+		}
+		if buf[n-1] == '\n' {
+			line, err := in.Next(n)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(line)), nil
+		}
+		scanned = n
+	}
+}
+
+// getTelnetCommand returns the telnet command recorded by decodeTelnetCommand.
+func getTelnetCommand(message remote.Message) (*telnetCommand, bool) {
+	cmd, ok := message.RPCInfo().Invocation().Extra(telnetExtraKey).(*telnetCommand)
+	return cmd, ok
+}
+
+// encodeTelnetReply writes the reply of the telnet command in text followed by the prompt.
+func (m *DubboCodec) encodeTelnetReply(message remote.Message, cmd *telnetCommand, out remote.ByteBuffer) error {
+	reply := cmd.reply
+	switch message.MessageType() {
+	case remote.Reply:
+		var result interface{}
+		if res, ok := message.Data().(interface{ GetResult() interface{} }); ok {
+			result = res.GetResult()
+		}
+		encoder := json.NewEncoder()
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		reply = fmt.Sprintf("result: %s\nelapsed: %d ms.", bytes.TrimSpace(encoder.Buffer()), m.getElapsed(message).Milliseconds())
+	case remote.Exception:
+		reply = fmt.Sprintf("Failed to invoke method %s, cause: %v", cmd.method, message.Data())
+	}
+	if reply != "" {
+		reply = strings.Replace(reply, "\n", "\r\n", -1) + "\r\n"
+	}
+	_, err := out.WriteString(reply + telnetPrompt)
+	return err
+}
+
+// telnetList lists the services, or the methods of the service specified by args.
+func (m *DubboCodec) telnetList(message remote.Message, args []string) string {
+	detail := len(args) > 0 && args[0] == "-l"
+	if detail {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		lines := []string{"PROVIDER:"}
+		for _, javaClassName := range m.javaClassNames() {
+			if detail {
+				svcName, _ := m.lookupService(message, javaClassName)
+				javaClassName += " -> " + svcName
+			}
+			lines = append(lines, javaClassName)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	javaClassName, svcName, ok := m.findService(message, args[0])
+	if !ok {
+		return "No such service: " + args[0]
+	}
+	methods := m.telnetMethods(message, svcName)
+	lines := make([]string, 0, len(methods)+1)
+	if detail {
+		lines = append(lines, javaClassName+" (as provider):")
+	}
+	for _, method := range methods {
+		if detail {
+			lines = append(lines, "\t"+method.javaName+"("+strings.Join(method.javaTypes, ",")+")")
+		} else {
+			lines = append(lines, method.javaName)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// javaClassNames returns all the InterfaceNames served by DubboCodec in order.
+func (m *DubboCodec) javaClassNames() []string {
+	names := make([]string, 0, len(m.opt.ServiceNames)+1)
+	if m.opt.JavaClassName != "" {
+		names = append(names, m.opt.JavaClassName)
+	}
+	for name := range m.opt.ServiceNames {
+		if name != m.opt.JavaClassName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupService returns the kitex ServiceName serving javaClassName.
+func (m *DubboCodec) lookupService(message remote.Message, javaClassName string) (string, bool) {
+	if name, ok := m.opt.ServiceNames[javaClassName]; ok {
+		return name, true
+	}
+	if javaClassName != m.opt.JavaClassName {
+		return "", false
+	}
+	if svcInfo := message.ServiceInfo(); svcInfo != nil {
+		return svcInfo.ServiceName, true
+	}
+	return "", true
+}
+
+// findService finds the service by its InterfaceName or simple class name.
+func (m *DubboCodec) findService(message remote.Message, name string) (javaClassName, svcName string, ok bool) {
+	for _, javaClassName = range m.javaClassNames() {
+		if javaClassName == name || javaClassName[strings.LastIndexByte(javaClassName, '.')+1:] == name {
+			svcName, ok = m.lookupService(message, javaClassName)
+			return
+		}
+	}
+	return "", "", false
+}
+
+// telnetMethod describes a method of the service in java.
+type telnetMethod struct {
+	name      string
+	javaName  string
+	javaTypes []string
+	numArgs   int
+}
+
+// telnetMethods returns the methods of the kitex service svcName in order of java names. The methods are collected
+// from the service info of message and the method annotations configured by WithFileDescriptor.
+func (m *DubboCodec) telnetMethods(message remote.Message, svcName string) []*telnetMethod {
+	names := make(map[string]bool)
+	if svcInfo := message.ServiceInfo(); svcInfo != nil && svcInfo.ServiceName == svcName {
+		for name := range svcInfo.Methods {
+			names[name] = true
+		}
+	}
+	prefix := svcName + "."
+	for key := range m.opt.MethodAnnotations {
+		if strings.HasPrefix(key, prefix) {
+			names[key[len(prefix):]] = true
+		}
+	}
+
+	methods := make([]*telnetMethod, 0, len(names))
+	for name := range names {
+		anno := m.opt.MethodAnnotations[prefix+name]
+		method := &telnetMethod{name: name, javaName: name}
+		if javaName, ok := anno.GetMethodName(); ok {
+			method.javaName = javaName
+		}
+		// the types derived from IDL are preferred since they are what the requests are encoded with
+		types, hasTypes := m.opt.MethodTypes[prefix+name]
+		if mi := getMethodInfo(message, svcName, name); mi != nil {
+			if arg, ok := getMessage(mi.NewArgs()); ok {
+				method.numArgs = numFields(arg)
+				if !hasTypes {
+					var err error
+					types, err = m.methodCache.GetTypes(arg, anno)
+					hasTypes = err == nil
+				}
+			}
+		}
+		if hasTypes {
+			method.javaTypes = javaTypeNames(types)
+		}
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].javaName < methods[j].javaName
+	})
+	return methods
+}
+
+// getMethodInfo returns the MethodInfo of method of the kitex service svcName, the service info of message would be
+// specified if it is a different service.
+func getMethodInfo(message remote.Message, svcName, method string) serviceinfo.MethodInfo {
+	svcInfo := message.ServiceInfo()
+	if svcInfo == nil || svcInfo.ServiceName != svcName {
+		var err error
+		if svcInfo, err = message.SpecifyServiceInfo(svcName, method); err != nil || svcInfo == nil {
+			return nil
+		}
+	}
+	return svcInfo.MethodInfo(method)
+}
+
+// decodeTelnetInvoke decodes `invoke [service.]method(args)` to the request of the method, and returns the java name
+// of the method. args are separated by comma in JSON, POJOs are passed as objects.
+func (m *DubboCodec) decodeTelnetInvoke(message remote.Message, line string) (string, error) {
+	start, end := strings.IndexByte(line, '('), strings.LastIndexByte(line, ')')
+	if start <= 0 || end < start {
+		return "", errors.New("invalid parameters, format: invoke [service.]method(args)")
+	}
+	target, method := "", strings.TrimSpace(line[:start])
+	if i := strings.LastIndexByte(method, '.'); i >= 0 {
+		target, method = method[:i], method[i+1:]
+	}
+	if target == "" {
+		if names := m.javaClassNames(); len(names) == 1 {
+			target = names[0]
+		} else {
+			return "", errors.New("please specify the service, format: invoke service.method(args)")
+		}
+	}
+	_, svcName, ok := m.findService(message, target)
+	if !ok {
+		return "", fmt.Errorf("no such service: %s", target)
+	}
+
+	v, err := json.NewDecoder([]byte("[" + line[start+1:end] + "]")).Decode()
+	if err != nil {
+		return "", fmt.Errorf("invalid json arguments, cause: %s", err)
+	}
+	args, _ := v.([]interface{})
+
+	name := ""
+	for _, mt := range m.telnetMethods(message, svcName) {
+		if mt.javaName == method && mt.numArgs == len(args) {
+			name = mt.name
+			break
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("no such method %s in service %s", method, target)
+	}
+	if _, err := message.SpecifyServiceInfo(svcName, name); err != nil {
+		return "", err
+	}
+	if err := codec.NewDataIfNeeded(name, message); err != nil {
+		return "", err
+	}
+	arg, ok := getMessage(message.Data())
+	if !ok {
+		return "", fmt.Errorf("invalid data: not hessian2.MessageReader")
+	}
+	if err := realizeGenericArgs(arg, args); err != nil {
+		return "", err
+	}
+	if err := codec.SetOrCheckMethodName(name, message); err != nil {
+		return "", err
+	}
+	m.beginInvocation(message)
+	return method, nil
+}
+
+// telnetCount shows the invocation statistics of the methods of the service specified by args.
+func (m *DubboCodec) telnetCount(message remote.Message, args []string) string {
+	if len(args) == 0 {
+		return "Please input service name, eg: \r\ncount XxxService\r\ncount XxxService xxxMethod"
+	}
+	javaClassName, _, ok := m.findService(message, args[0])
+	if !ok {
+		return "No such service: " + args[0]
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "method\ttotal\tfailed\tactive\taverage\tmax")
+	for _, method := range m.stats.methods(javaClassName) {
+		if len(args) > 1 && args[1] != method.name {
+			continue
+		}
+		total := atomic.LoadInt64(&method.total)
+		var average int64
+		if total > 0 {
+			average = atomic.LoadInt64(&method.elapsed) / total
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%dms\t%dms\n", method.name, total, atomic.LoadInt64(&method.failed),
+			atomic.LoadInt64(&method.active), time.Duration(average).Milliseconds(),
+			time.Duration(atomic.LoadInt64(&method.maxElapsed)).Milliseconds())
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// telnetStatus shows the status of the provider, the details of resources are shown with -l.
+func (m *DubboCodec) telnetStatus(args []string) string {
+	status := "OK"
+	offline := m.registrations.offlineNames()
+	if len(offline) > 0 {
+		status = "WARN"
+	}
+	if len(args) == 0 || args[0] != "-l" {
+		return status
+	}
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	registryStatus, registryMessage := "OK", ""
+	if len(offline) > 0 {
+		registryStatus, registryMessage = "WARN", "offline: "+strings.Join(offline, ",")
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "resource\tstatus\tmessage")
+	fmt.Fprintf(w, "registry\t%s\t%s\n", registryStatus, registryMessage)
+	fmt.Fprintf(w, "memory\tOK\theap:%dM,sys:%dM\n", memStats.HeapAlloc>>20, memStats.Sys>>20)
+	fmt.Fprintf(w, "goroutine\tOK\t%d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "summary\t%s\t\n", status)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// telnetSetOnline registers or deregisters the services matching pattern with the registries wrapped by
+// TelnetRegistry.
+func (m *DubboCodec) telnetSetOnline(pattern string, online bool) string {
+	names, err := m.registrations.setOnline(pattern, online)
+	if err != nil {
+		return err.Error()
+	}
+	if len(names) == 0 {
+		return "No service matched, please wrap the registry with DubboCodec.TelnetRegistry."
+	}
+	return "OK"
+}
+
+// methodStats is the invocation statistics of a method, elapsed time is in nanoseconds.
+type methodStats struct {
+	total      int64
+	failed     int64
+	active     int64
+	elapsed    int64
+	maxElapsed int64
+	name       string
+}
+
+// invocationStats records the invocation statistics of the methods for the count command.
+// key: InterfaceName + "." + method name, val: *methodStats
+type invocationStats struct {
+	sync.Map
+}
+
+func (s *invocationStats) get(javaClassName, method string) *methodStats {
+	key := javaClassName + "." + method
+	if stats, ok := s.Load(key); ok {
+		return stats.(*methodStats)
+	}
+	stats, _ := s.LoadOrStore(key, &methodStats{name: method})
+	return stats.(*methodStats)
+}
+
+// methods returns the statistics of the methods of javaClassName in order of names.
+func (s *invocationStats) methods(javaClassName string) []*methodStats {
+	prefix := javaClassName + "."
+	var res []*methodStats
+	s.Range(func(key, value interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			res = append(res, value.(*methodStats))
+		}
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+// invocation is an invocation being counted.
+type invocation struct {
+	stats *methodStats
+	start time.Time
+}
+
+// beginInvocation counts the request decoded on the server side when telnet is enabled.
+func (m *DubboCodec) beginInvocation(message remote.Message) {
+	setter, ok := message.RPCInfo().Invocation().(rpcinfo.InvocationSetter)
+	if !ok {
+		return
+	}
+	stats := m.stats.get(m.getJavaClassName(message), message.RPCInfo().Invocation().MethodName())
+	atomic.AddInt64(&stats.active, 1)
+	setter.SetExtra(invocationExtraKey, &invocation{stats: stats, start: time.Now()})
+}
+
+// endInvocation counts the response of the request counted by beginInvocation.
+func (m *DubboCodec) endInvocation(message remote.Message) {
+	inv, ok := message.RPCInfo().Invocation().Extra(invocationExtraKey).(*invocation)
+	if !ok {
+		return
+	}
+	elapsed := int64(time.Since(inv.start))
+	stats := inv.stats
+	atomic.AddInt64(&stats.active, -1)
+	atomic.AddInt64(&stats.total, 1)
+	if message.MessageType() == remote.Exception {
+		atomic.AddInt64(&stats.failed, 1)
+	}
+	atomic.AddInt64(&stats.elapsed, elapsed)
+	for {
+		maxElapsed := atomic.LoadInt64(&stats.maxElapsed)
+		if elapsed <= maxElapsed || atomic.CompareAndSwapInt64(&stats.maxElapsed, maxElapsed, elapsed) {
+			break
+		}
+	}
+}
+
+// getElapsed returns the time elapsed since the request counted by beginInvocation was decoded.
+func (m *DubboCodec) getElapsed(message remote.Message) time.Duration {
+	if inv, ok := message.RPCInfo().Invocation().Extra(invocationExtraKey).(*invocation); ok {
+		return time.Since(inv.start)
+	}
+	return 0
+}
+
+// javaTypeNames converts the parameter types in descriptor form to java type names, e.g.
+// "Ljava/lang/String;[I" -> ["java.lang.String", "int[]"].
+func javaTypeNames(types string) []string {
+	var names []string
+	for i := 0; i < len(types); i++ {
+		dims := 0
+		for ; i < len(types) && types[i] == '['; i++ {
+			dims++
+		}
+		if i >= len(types) {
+			break
+		}
+		var name string
+		switch types[i] {
+		case 'L':
+			end := strings.IndexByte(types[i:], ';')
+			if end < 0 {
+				end = len(types) - i
+			}
+			name = strings.Replace(types[i+1:i+end], "/", ".", -1)
+			i += end
+		case 'Z':
+			name = "boolean"
+		case 'B':
+			name = "byte"
+		case 'C':
+			name = "char"
+		case 'S':
+			name = "short"
+		case 'I':
+			name = "int"
+		case 'J':
+			name = "long"
+		case 'F':
+			name = "float"
+		case 'D':
+			name = "double"
+		case 'V':
+			name = "void"
+		default:
+			name = string(types[i])
+		}
+		names = append(names, name+strings.Repeat("[]", dims))
+	}
+	return names
+}
+
+// numFields returns the number of the arguments of the generated arg struct.
+func numFields(arg interface{}) int {
+	val := reflect.Indirect(reflect.ValueOf(arg))
+	if val.Kind() != reflect.Struct {
+		return 0
+	}
+	return val.NumField()
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/kitex/pkg/registry"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+)
+
+type registration struct {
+	r      registry.Registry
+	info   *registry.Info
+	online bool
+}
+
+// name returns the InterfaceName of the registered service, or the kitex ServiceName if it is absent.
+func (reg *registration) name() string {
+	if name := reg.info.Tags[registries.DubboServiceInterfaceKey]; name != "" {
+		return name
+	}
+	return reg.info.ServiceName
+}
+
+// registrations records the services registered by the registries returned by TelnetRegistry,
+// which are taken offline and online by the telnet commands.
+type registrations struct {
+	// opMu serializes setOnline, the registries are called without holding mu.
+	opMu sync.Mutex
+	mu   sync.Mutex
+	regs []*registration
+}
+
+func (rs *registrations) add(reg *registration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.regs = append(rs.regs, reg)
+}
+
+// remove removes the registration of info and returns it.
+func (rs *registrations) remove(r registry.Registry, info *registry.Info) (*registration, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for i, reg := range rs.regs {
+		if reg.r == r && reg.info == info {
+			rs.regs = append(rs.regs[:i], rs.regs[i+1:]...)
+			return reg, true
+		}
+	}
+	return nil, false
+}
+
+// setOnline registers or deregisters the services whose names match pattern, all the services are matched if pattern
+// is empty. The names of the services changed are returned.
+func (rs *registrations) setOnline(pattern string, online bool) ([]string, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile("^(?:" + pattern + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid service pattern %s: %s", pattern, err)
+		}
+	}
+	rs.opMu.Lock()
+	defer rs.opMu.Unlock()
+	// the registries may block on the network, so they are called with a snapshot of the matched registrations
+	var matched []*registration
+	rs.mu.Lock()
+	for _, reg := range rs.regs {
+		if reg.online != online && (re == nil || re.MatchString(reg.name())) {
+			matched = append(matched, reg)
+		}
+	}
+	rs.mu.Unlock()
+
+	var names []string
+	for _, reg := range matched {
+		var err error
+		if online {
+			err = reg.r.Register(reg.info)
+		} else {
+			err = reg.r.Deregister(reg.info)
+		}
+		if err != nil {
+			return names, fmt.Errorf("%s failed: %s", reg.name(), err)
+		}
+		rs.mu.Lock()
+		reg.online = online
+		rs.mu.Unlock()
+		names = append(names, reg.name())
+	}
+	return names, nil
+}
+
+// offlineNames returns the names of the services taken offline.
+func (rs *registrations) offlineNames() []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var names []string
+	for _, reg := range rs.regs {
+		if !reg.online {
+			names = append(names, reg.name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TelnetRegistry wraps r so that the services registered by kitex server could be taken offline and online again
+// by the telnet commands `offline [service]` and `online [service]` served by this codec, which is the same as the
+// QoS commands of dubbo-java:
+//
+//	codec := dubbo.NewDubboCodec(
+//		dubbo.WithJavaClassName("org.cloudwego.kitex.samples.api.GreetProvider"),
+//		dubbo.WithTelnet(),
+//	)
+//	svr := greetservice.NewServer(new(GreetServiceImpl),
+//		server.WithRegistry(codec.TelnetRegistry(zkRegistry)),
+//		server.WithCodec(codec),
+//	)
+func (m *DubboCodec) TelnetRegistry(r registry.Registry) registry.Registry {
+	if r == nil {
+		panic("Please pass in a valid Registry.")
+	}
+	return &telnetRegistry{Registry: r, regs: m.registrations}
+}
+
+type telnetRegistry struct {
+	registry.Registry
+	regs *registrations
+}
+
+func (t *telnetRegistry) Register(info *registry.Info) error {
+	if err := t.Registry.Register(info); err != nil {
+		return err
+	}
+	t.regs.add(&registration{r: t.Registry, info: info, online: true})
+	return nil
+}
+
+func (t *telnetRegistry) Deregister(info *registry.Info) error {
+	// services taken offline have been deregistered
+	if reg, ok := t.regs.remove(t.Registry, info); ok && !reg.online {
+		return nil
+	}
+	return t.Registry.Deregister(info)
+}
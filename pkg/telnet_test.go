@@ -0,0 +1,239 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/registry"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+	"github.com/stretchr/testify/assert"
+)
+
+var telnetServiceInfo = &serviceinfo.ServiceInfo{
+	ServiceName: "GreetService",
+	Methods: map[string]serviceinfo.MethodInfo{
+		"Greet": serviceinfo.NewMethodInfo(nil,
+			func() interface{} { return new(genericTestArgs) },
+			func() interface{} { return new(genericTestResult) },
+			false,
+		),
+	},
+}
+
+// telnet sends line to codec and returns the reply, the handler replies result if the command is invoke.
+func telnet(t *testing.T, codec *DubboCodec, line string, result interface{}) string {
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+		rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+	recvMsg := remote.NewMessage(nil, telnetServiceInfo, ri, remote.Call, remote.Server)
+	assert.Nil(t, codec.Decode(context.Background(), recvMsg, remote.NewReaderBuffer([]byte(line+"\r\n"))))
+
+	var sendMsg remote.Message
+	switch {
+	case recvMsg.MessageType() == remote.Heartbeat:
+		sendMsg = remote.NewMessage(nil, telnetServiceInfo, ri, remote.Heartbeat, remote.Server)
+	case result != nil:
+		sendMsg = remote.NewMessage(result, telnetServiceInfo, ri, remote.Reply, remote.Server)
+	default:
+		sendMsg = remote.NewMessage(errors.New("failed"), telnetServiceInfo, ri, remote.Exception, remote.Server)
+	}
+	out := remote.NewReaderWriterBuffer(1024)
+	assert.Nil(t, codec.Encode(context.Background(), sendMsg, out))
+	buf, err := out.Bytes()
+	assert.Nil(t, err)
+	return string(buf)
+}
+
+func TestTelnet(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithTelnet())
+
+	tests := []struct {
+		desc     string
+		line     string
+		result   interface{}
+		expected string
+	}{
+		{
+			desc:     "empty",
+			line:     "",
+			expected: "dubbo>",
+		},
+		{
+			desc:     "ls",
+			line:     "ls",
+			expected: "PROVIDER:\r\n" + testJavaClassName + "\r\ndubbo>",
+		},
+		{
+			desc:     "ls -l",
+			line:     "ls -l",
+			expected: "PROVIDER:\r\n" + testJavaClassName + " -> GreetService\r\ndubbo>",
+		},
+		{
+			desc:     "ls service",
+			line:     "ls GreetProvider",
+			expected: "Greet\r\ndubbo>",
+		},
+		{
+			desc:     "ls -l service",
+			line:     "ls -l " + testJavaClassName,
+			expected: testJavaClassName + " (as provider):\r\n\tGreet(org.cloudwego.kitex.samples.api.GreetRequest,java.lang.Integer)\r\ndubbo>",
+		},
+		{
+			desc:     "ls non-exist service",
+			line:     "ls EchoProvider",
+			expected: "No such service: EchoProvider\r\ndubbo>",
+		},
+		{
+			desc:     "invoke",
+			line:     `invoke GreetProvider.Greet({"req":"world"}, 1)`,
+			result:   &genericTestResult{Success: &genericTestReq{Req: "hello world"}},
+			expected: "result: {\"req\":\"hello world\"}\r\nelapsed: 0 ms.\r\ndubbo>",
+		},
+		{
+			desc:     "invoke default service",
+			line:     `invoke Greet(null, 1)`,
+			expected: "Failed to invoke method Greet, cause: failed\r\ndubbo>",
+		},
+		{
+			desc:     "invoke non-exist method",
+			line:     `invoke Echo("world")`,
+			expected: "no such method Echo in service " + testJavaClassName + "\r\ndubbo>",
+		},
+		{
+			desc:     "invoke invalid arguments",
+			line:     `invoke Greet(world)`,
+			expected: "invalid json arguments, cause: invalid character 'w' looking for beginning of value\r\ndubbo>",
+		},
+		{
+			desc:     "count",
+			line:     "count GreetProvider",
+			expected: "method  total  failed  active  average  max\r\nGreet   2      1       0       0ms      0ms\r\ndubbo>",
+		},
+		{
+			desc:     "unsupported",
+			line:     "trace GreetProvider",
+			expected: "Unsupported command: trace\r\ndubbo>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, telnet(t, codec, test.line, test.result))
+		})
+	}
+
+	ri := rpcinfo.NewRPCInfo(nil, rpcinfo.NewEndpointInfo("", "", nil, nil),
+		rpcinfo.NewServerInvocation(), rpcinfo.NewRPCConfig(), nil)
+	msg := remote.NewMessage(nil, telnetServiceInfo, ri, remote.Call, remote.Server)
+	in := remote.NewReaderBuffer([]byte(`invoke Greet({"req":"world"}, 1)` + "\r\n"))
+	assert.Nil(t, codec.Decode(context.Background(), msg, in))
+	assert.Equal(t, "Greet", ri.Invocation().MethodName())
+	assert.Equal(t, &genericTestArgs{Req: &genericTestReq{Req: "world"}, Size: 1}, msg.Data())
+	assert.Equal(t, errTelnetExit, codec.Decode(context.Background(), msg, remote.NewReaderBuffer([]byte("exit\r\n"))))
+}
+
+func TestReadTelnetLine(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithMaxPayload(32))
+	in := remote.NewReaderBuffer([]byte("ls\r\n count GreetProvider \r\n"))
+	line, err := codec.readTelnetLine(in)
+	assert.Nil(t, err)
+	assert.Equal(t, "ls", line)
+	line, err = codec.readTelnetLine(in)
+	assert.Equal(t, "count GreetProvider", line)
+	assert.Nil(t, err)
+
+	_, err = codec.readTelnetLine(remote.NewReaderBuffer([]byte("invoke GreetProvider.Greet(\"hello world\")\r\n")))
+	var payloadErr *ExceedPayloadLimitError
+	assert.True(t, errors.As(err, &payloadErr))
+}
+
+type mockRegistry struct {
+	registered map[string]bool
+}
+
+func (r *mockRegistry) Register(info *registry.Info) error {
+	r.registered[info.ServiceName] = true
+	return nil
+}
+
+func (r *mockRegistry) Deregister(info *registry.Info) error {
+	delete(r.registered, info.ServiceName)
+	return nil
+}
+
+func TestTelnetOnline(t *testing.T) {
+	codec := NewDubboCodec(WithJavaClassName(testJavaClassName), WithTelnet())
+	assert.Equal(t, "No service matched, please wrap the registry with DubboCodec.TelnetRegistry.\r\ndubbo>",
+		telnet(t, codec, "offline", nil))
+
+	inner := &mockRegistry{registered: make(map[string]bool)}
+	r := codec.TelnetRegistry(inner)
+	greetInfo := &registry.Info{
+		ServiceName: "GreetService",
+		Tags:        map[string]string{registries.DubboServiceInterfaceKey: testJavaClassName},
+	}
+	echoInfo := &registry.Info{ServiceName: "EchoService"}
+	assert.Nil(t, r.Register(greetInfo))
+	assert.Nil(t, r.Register(echoInfo))
+	defer func() {
+		assert.Nil(t, r.Deregister(greetInfo))
+		assert.Nil(t, r.Deregister(echoInfo))
+		assert.Empty(t, inner.registered)
+	}()
+
+	// the services are taken offline by the codec wrapping the registry only
+	other := NewDubboCodec(WithJavaClassName(testJavaClassName), WithTelnet())
+	assert.Equal(t, "No service matched, please wrap the registry with DubboCodec.TelnetRegistry.\r\ndubbo>",
+		telnet(t, other, "offline", nil))
+	assert.Equal(t, "OK\r\ndubbo>", telnet(t, codec, "offline org.cloudwego.*", nil))
+	assert.Equal(t, map[string]bool{"EchoService": true}, inner.registered)
+	assert.Equal(t, "WARN\r\ndubbo>", telnet(t, codec, "status", nil))
+	assert.Contains(t, telnet(t, codec, "status -l", nil), "registry   WARN    offline: "+testJavaClassName)
+	assert.Equal(t, "OK\r\ndubbo>", telnet(t, codec, "offline", nil))
+	assert.Empty(t, inner.registered)
+
+	assert.Equal(t, "OK\r\ndubbo>", telnet(t, codec, "online", nil))
+	assert.Equal(t, map[string]bool{"GreetService": true, "EchoService": true}, inner.registered)
+	assert.Equal(t, "OK\r\ndubbo>", telnet(t, codec, "status", nil))
+	assert.Equal(t, "invalid service pattern (: error parsing regexp: missing closing ): `^(?:()$`\r\ndubbo>",
+		telnet(t, codec, "online (", nil))
+}
+
+func TestTelnetIDLTypes(t *testing.T) {
+	codec := NewDubboCodec(
+		WithJavaClassName(testJavaClassName),
+		WithTelnet(),
+		WithFileDescriptor(newTestFileDescriptor(
+			newTestMethodDescriptor("Greet", "", "string", "i64"),
+		)),
+	)
+	assert.Equal(t, testJavaClassName+" (as provider):\r\n\tGreet(java.lang.String,java.lang.Long)\r\ndubbo>",
+		telnet(t, codec, "ls -l "+testJavaClassName, nil))
+}
+
+func TestJavaTypeNames(t *testing.T) {
+	assert.Equal(t, []string{"java.lang.String", "int", "long[]", "java.util.List[][]", "boolean"},
+		javaTypeNames("Ljava/lang/String;I[J[[Ljava/util/List;Z"))
+	assert.Nil(t, javaTypeNames(""))
+}
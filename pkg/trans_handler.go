@@ -0,0 +1,180 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/cloudwego/kitex/pkg/endpoint"
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/cloudwego/kitex/pkg/remote"
+	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+)
+
+// NewSvrTransHandlerFactory wraps the ServerTransHandlerFactory of kitex server. If inner is nil,
+// netpoll.NewSvrTransHandlerFactory() would be used.
+// When the server is shutting down gracefully, the wrapped ServerTransHandler sends READONLY_EVENT to every
+// active dubbo connection before draining, which is the same as dubbo-java providers. Consumers receiving the event
+// would not send new requests to this server.
+//
+//	svr := greetservice.NewServer(new(GreetServiceImpl),
+//		server.WithTransHandlerFactory(dubbo.NewSvrTransHandlerFactory(nil)),
+//		server.WithCodec(dubbo.NewDubboCodec(...)),
+//	)
+func NewSvrTransHandlerFactory(inner remote.ServerTransHandlerFactory) remote.ServerTransHandlerFactory {
+	if inner == nil {
+		inner = netpoll.NewSvrTransHandlerFactory()
+	}
+	return &svrTransHandlerFactory{inner: inner}
+}
+
+type svrTransHandlerFactory struct {
+	inner remote.ServerTransHandlerFactory
+}
+
+func (f *svrTransHandlerFactory) NewTransHandler(opt *remote.ServerOption) (remote.ServerTransHandler, error) {
+	hdlr, err := f.inner.NewTransHandler(opt)
+	if err != nil {
+		return nil, err
+	}
+	_, detection := opt.Codec.(*detectionCodec)
+	return &svrTransHandler{
+		ServerTransHandler: hdlr,
+		detection:          detection,
+		conns:              make(map[net.Conn]*connState),
+	}, nil
+}
+
+var (
+	_ remote.GracefulShutdown       = (*svrTransHandler)(nil)
+	_ remote.InvokeHandleFuncSetter = (*svrTransHandler)(nil)
+)
+
+type svrTransHandler struct {
+	remote.ServerTransHandler
+	// detection is set if the server serves multiple protocols with the codec returned by NewDetectionCodec,
+	// READONLY_EVENT is only sent to the connections known to be dubbo ones then.
+	detection bool
+
+	mu sync.Mutex
+	// key: active connection, val: the state of this connection
+	conns map[net.Conn]*connState
+}
+
+type connState struct {
+	// mu serializes writes of the connection
+	mu sync.Mutex
+	// dubbo and nonDubbo record the protocol detected by the codec returned by NewDetectionCodec,
+	// both of them are unset before the first request is decoded.
+	dubbo    bool
+	nonDubbo bool
+}
+
+func (t *svrTransHandler) OnActive(ctx context.Context, conn net.Conn) (context.Context, error) {
+	ctx, err := t.ServerTransHandler.OnActive(ctx, conn)
+	if err != nil {
+		return ctx, err
+	}
+	t.mu.Lock()
+	t.conns[conn] = new(connState)
+	t.mu.Unlock()
+	return ctx, nil
+}
+
+func (t *svrTransHandler) OnInactive(ctx context.Context, conn net.Conn) {
+	t.mu.Lock()
+	delete(t.conns, conn)
+	t.mu.Unlock()
+	t.ServerTransHandler.OnInactive(ctx, conn)
+}
+
+// Read records the protocol of the connection once the request is decoded.
+func (t *svrTransHandler) Read(ctx context.Context, conn net.Conn, msg remote.Message) (context.Context, error) {
+	ctx, err := t.ServerTransHandler.Read(ctx, conn, msg)
+	isDubbo, detected := detectedProtocol(msg)
+	if !detected {
+		return ctx, err
+	}
+	t.mu.Lock()
+	state, ok := t.conns[conn]
+	t.mu.Unlock()
+	if ok {
+		state.mu.Lock()
+		if isDubbo {
+			state.dubbo = true
+		} else {
+			state.nonDubbo = true
+		}
+		state.mu.Unlock()
+	}
+	return ctx, err
+}
+
+// Write serializes the writes of responses and events sent by GracefulShutdown on the same connection.
+func (t *svrTransHandler) Write(ctx context.Context, conn net.Conn, send remote.Message) (context.Context, error) {
+	t.mu.Lock()
+	state, ok := t.conns[conn]
+	t.mu.Unlock()
+	if ok {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+	}
+	return t.ServerTransHandler.Write(ctx, conn, send)
+}
+
+func (t *svrTransHandler) SetInvokeHandleFunc(inkHdlFunc endpoint.Endpoint) {
+	if setter, ok := t.ServerTransHandler.(remote.InvokeHandleFuncSetter); ok {
+		setter.SetInvokeHandleFunc(inkHdlFunc)
+	}
+}
+
+// GracefulShutdown sends READONLY_EVENT to all the active dubbo connections, then shuts down the inner ServerTransHandler.
+func (t *svrTransHandler) GracefulShutdown(ctx context.Context) error {
+	t.mu.Lock()
+	conns := make(map[net.Conn]*connState, len(t.conns))
+	for conn, state := range t.conns {
+		conns[conn] = state
+	}
+	t.mu.Unlock()
+
+	for conn, state := range conns {
+		// dubbo-java does not reply READONLY_EVENT
+		pkg, _, err := buildEventRequest(dubbo_spec.READONLY_EVENT, false)
+		if err != nil {
+			return err
+		}
+		state.mu.Lock()
+		if !state.nonDubbo && (state.dubbo || !t.detection) {
+			_, err = conn.Write(pkg)
+		}
+		state.mu.Unlock()
+		if err != nil {
+			klog.Warnf("KITEX: dubbo send READONLY_EVENT to %s failed: %s", conn.RemoteAddr(), err)
+		}
+	}
+
+	if g, ok := t.ServerTransHandler.(remote.GracefulShutdown); ok {
+		return g.GracefulShutdown(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,593 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/codes"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/metadata"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/status"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/cloudwego/kitex/pkg/streaming"
+	"github.com/cloudwego/kitex/transport"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	hessian2_exception "github.com/kitex-contrib/codec-dubbo/pkg/hessian2/exception"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// tripleServiceKey is the key of the tripleService in the Extra of the ServiceInfo created by NewTripleServiceInfo.
+	tripleServiceKey = "dubbo_triple"
+
+	// tripleHessianSerializeType is the serializeType of hessian2 in the triple wrappers,
+	// dubbo-java names hessian2 as hessian4 on the wire.
+	tripleHessianSerializeType = "hessian4"
+)
+
+// headers and trailers defined by TripleHeaderEnum of dubbo-java
+const (
+	tripleServiceGroupHeader   = "tri-service-group"
+	tripleServiceVersionHeader = "tri-service-version"
+	// tripleExceptionCodeTrailer is set by providers if the TripleResponseWrapper carries the exception
+	// thrown by the method instead of the result.
+	tripleExceptionCodeTrailer = "tri-exception-code"
+	tripleExceptionCodeBiz     = "1"
+)
+
+// field numbers of TripleRequestWrapper and TripleResponseWrapper defined by dubbo-java
+const (
+	tripleSerializeTypeField = 1
+
+	tripleRequestArgsField     = 2
+	tripleRequestArgTypesField = 3
+
+	tripleResponseDataField = 2
+	tripleResponseTypeField = 3
+)
+
+var (
+	_ iface.Encoder = (*tripleEncoder)(nil)
+	_ iface.Decoder = (*tripleDecoder)(nil)
+)
+
+// tripleService keeps the kitex service wrapped by NewTripleServiceInfo.
+type tripleService struct {
+	packageName string
+	// group and version are specified by WithServiceKeys, requests of other groups and versions are rejected.
+	group   string
+	version string
+	// methods maps the kitex method names to the triple methods.
+	methods map[string]*tripleMethod
+	// methodTypes and resultTypes map the kitex method names to the types derived from IDL by WithFileDescriptor,
+	// methodCache derives the types from the runtime values of the methods absent from them.
+	methodTypes map[string]string
+	resultTypes map[string]string
+	methodCache hessian2.MethodCache
+}
+
+// tripleMethod is a kitex method served or invoked by the triple protocol.
+type tripleMethod struct {
+	service  *tripleService
+	name     string
+	javaName string
+	info     serviceinfo.MethodInfo
+	// wrapped reports whether the args and results are carried by TripleRequestWrapper and TripleResponseWrapper.
+	wrapped    bool
+	annotation *hessian2.MethodAnnotation
+}
+
+// NewTripleServiceInfo converts the ServiceInfo generated by kitex to the one served and invoked by
+// the triple protocol of dubbo 3, which is transported by kitex gRPC.
+// The request path is /{InterfaceName}/{method} where InterfaceName is specified by WithJavaClassName or
+// WithJavaClassNames and the java method names are specified by the method annotations of WithFileDescriptor.
+// The group and version of WithServiceKeys are carried by the tri-service-group and tri-service-version headers.
+// Services generated by kitex protobuf are served in the protobuf mode, others are served in the wrapper mode:
+// arguments and results are serialized by hessian2 and wrapped by TripleRequestWrapper and TripleResponseWrapper.
+func NewTripleServiceInfo(svcInfo *serviceinfo.ServiceInfo, opts ...Option) *serviceinfo.ServiceInfo {
+	o := newOptions(opts)
+	javaClassName := o.JavaClassName
+	if name, ok := o.JavaClassNames[svcInfo.ServiceName]; ok {
+		javaClassName = name
+	}
+	key := o.ServiceKeys[svcInfo.ServiceName]
+	ts := &tripleService{
+		group:       key.Group,
+		version:     key.Version,
+		methods:     make(map[string]*tripleMethod, len(svcInfo.Methods)),
+		methodTypes: make(map[string]string),
+		resultTypes: make(map[string]string),
+	}
+	serviceName := javaClassName
+	if idx := strings.LastIndexByte(javaClassName, '.'); idx >= 0 {
+		ts.packageName, serviceName = javaClassName[:idx], javaClassName[idx+1:]
+	}
+
+	methods := make(map[string]serviceinfo.MethodInfo, len(svcInfo.Methods))
+	for name, info := range svcInfo.Methods {
+		m := &tripleMethod{
+			service:    ts,
+			name:       name,
+			javaName:   name,
+			info:       info,
+			wrapped:    svcInfo.PayloadCodec != serviceinfo.Protobuf,
+			annotation: o.MethodAnnotations[svcInfo.ServiceName+"."+name],
+		}
+		if javaName, exists := m.annotation.GetMethodName(); exists {
+			m.javaName = javaName
+		}
+		if _, exists := methods[m.javaName]; exists {
+			panic(fmt.Sprintf("java method %s of %s is served by multiple kitex methods.", m.javaName, javaClassName))
+		}
+		ts.methods[name] = m
+		if types, ok := o.MethodTypes[svcInfo.ServiceName+"."+name]; ok {
+			ts.methodTypes[name] = types
+		}
+		if types, ok := o.ResultTypes[svcInfo.ServiceName+"."+name]; ok {
+			ts.resultTypes[name] = types
+		}
+		if m.wrapped {
+			methods[m.javaName] = serviceinfo.NewMethodInfo(m.handler, m.newArgs, m.newResult, info.OneWay(),
+				serviceinfo.WithStreamingMode(serviceinfo.StreamingUnary))
+		} else {
+			methods[m.javaName] = serviceinfo.NewMethodInfo(m.protobufHandler, info.NewArgs, info.NewResult, info.OneWay(),
+				serviceinfo.WithStreamingMode(info.StreamingMode()))
+		}
+	}
+
+	extra := make(map[string]interface{}, len(svcInfo.Extra)+2)
+	for k, v := range svcInfo.Extra {
+		extra[k] = v
+	}
+	extra["PackageName"] = ts.packageName
+	extra[tripleServiceKey] = ts
+	return &serviceinfo.ServiceInfo{
+		ServiceName:     serviceName,
+		HandlerType:     svcInfo.HandlerType,
+		Methods:         methods,
+		PayloadCodec:    serviceinfo.Protobuf,
+		KiteXGenVersion: svcInfo.KiteXGenVersion,
+		Extra:           extra,
+	}
+}
+
+// NewTripleClient creates a kitex gRPC client invoking the triple service, svcInfo must be created by NewTripleServiceInfo.
+// Methods are called with the kitex method names and the args and results generated by kitex, e.g.
+// Call(ctx, "Greet", &hello.GreetServiceGreetArgs{Req: "world"}, &hello.GreetServiceGreetResult{}).
+func NewTripleClient(destService string, svcInfo *serviceinfo.ServiceInfo, opts ...client.Option) (client.Client, error) {
+	ts, ok := svcInfo.Extra[tripleServiceKey].(*tripleService)
+	if !ok {
+		return nil, errors.New("ServiceInfo of the triple client should be created by dubbo.NewTripleServiceInfo")
+	}
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+	options = append(options, opts...)
+
+	kc, err := client.NewClient(svcInfo, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &tripleClient{Client: kc, service: ts}, nil
+}
+
+type tripleClient struct {
+	client.Client
+	service *tripleService
+}
+
+// Call invokes the java method of the kitex method, the args and results are wrapped in the wrapper mode.
+// Exceptions thrown by the java method are returned as errors in the wrapper mode, and other failures
+// are returned as DubboStatusError converted from the grpc-status.
+func (c *tripleClient) Call(ctx context.Context, method string, request, response interface{}) error {
+	m, ok := c.service.methods[method]
+	if !ok {
+		return c.Client.Call(ctx, method, request, response)
+	}
+	if c.service.group != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tripleServiceGroupHeader, c.service.group)
+	}
+	if c.service.version != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tripleServiceVersionHeader, c.service.version)
+	}
+	if !m.wrapped {
+		return convertTripleError(c.Client.Call(ctx, m.javaName, request, response))
+	}
+	result := &tripleResult{method: m, data: response}
+	if err := c.Client.Call(ctx, m.javaName, &tripleArgs{method: m, data: request}, result); err != nil {
+		return convertTripleError(err)
+	}
+	return result.exception
+}
+
+// convertTripleError converts the grpc-status of the triple response to DubboStatusError,
+// following the mapping between the triple status and the dubbo status of dubbo-java.
+func convertTripleError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err
+	}
+	var code dubbo_spec.StatusCode
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		code = dubbo_spec.StatusClientTimeout
+	case codes.Unimplemented, codes.NotFound:
+		code = dubbo_spec.StatusServiceNotFound
+	case codes.ResourceExhausted:
+		code = dubbo_spec.StatusServerPoolExhausted
+	case codes.InvalidArgument:
+		code = dubbo_spec.StatusBadRequest
+	case codes.Unknown:
+		code = dubbo_spec.StatusServiceError
+	default:
+		code = dubbo_spec.StatusServerError
+	}
+	return newStatusError(code, st.Message())
+}
+
+func (m *tripleMethod) newArgs() interface{} {
+	return &tripleArgs{method: m, data: m.info.NewArgs()}
+}
+
+func (m *tripleMethod) newResult() interface{} {
+	return &tripleResult{method: m, data: m.info.NewResult()}
+}
+
+// handler unwraps the args and results for the kitex handler. The args are received from the stream
+// unless server.WithCompatibleMiddlewareForUnary is specified.
+func (m *tripleMethod) handler(ctx context.Context, handler, arg, result interface{}) error {
+	if st, ok := arg.(*streaming.Args); ok {
+		args, res := m.newArgs(), m.newResult()
+		if err := st.Stream.RecvMsg(args); err != nil {
+			return err
+		}
+		if err := m.handler(ctx, handler, args, res); err != nil {
+			return err
+		}
+		return st.Stream.SendMsg(res)
+	}
+	if err := m.service.checkRequest(ctx); err != nil {
+		return err
+	}
+	res := result.(*tripleResult)
+	err := m.info.Handler()(ctx, handler, arg.(*tripleArgs).data, res.data)
+	// errors in the outer layer and the ones specifying grpc-status are replied as they are
+	var statusErr status.Iface
+	if getStatusCode(err) != dubbo_spec.StatusOK || errors.As(err, &statusErr) {
+		return err
+	}
+	if exception, ok := hessian2_exception.FromError(err); ok {
+		res.exception = exception
+	} else if err != nil {
+		res.exception = hessian2_exception.NewException(err.Error())
+	}
+	if res.exception != nil {
+		// dubbo-java consumers read the exception from the TripleResponseWrapper when the trailer is set
+		return nphttp2.SetTrailer(ctx, metadata.Pairs(tripleExceptionCodeTrailer, tripleExceptionCodeBiz))
+	}
+	return nil
+}
+
+// protobufHandler checks the request before calling the handler generated by kitex protobuf.
+func (m *tripleMethod) protobufHandler(ctx context.Context, handler, arg, result interface{}) error {
+	if err := m.service.checkRequest(ctx); err != nil {
+		return err
+	}
+	return m.info.Handler()(ctx, handler, arg, result)
+}
+
+// checkRequest rejects the requests of other packages, groups or versions with codes.Unimplemented,
+// which is replied by gRPC servers for unknown services.
+func (s *tripleService) checkRequest(ctx context.Context) error {
+	if ri := rpcinfo.GetRPCInfo(ctx); ri != nil {
+		if pkg := ri.Invocation().PackageName(); pkg != s.packageName {
+			return status.Errorf(codes.Unimplemented,
+				"triple requested package: %s, kitex service specified package: %s", pkg, s.packageName)
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := ServiceKey{
+		Group:   tripleHeader(md, tripleServiceGroupHeader),
+		Version: tripleHeader(md, tripleServiceVersionHeader),
+	}
+	if key.Group != s.group || key.Version != s.version {
+		return status.Errorf(codes.Unimplemented,
+			"triple requested group: %s, version: %s, kitex service specified group: %s, version: %s",
+			key.Group, key.Version, s.group, s.version)
+	}
+	return nil
+}
+
+func tripleHeader(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// argTypes returns the parameter types of the method, which are derived from IDL if it is provided by
+// WithFileDescriptor, otherwise from the annotation and the runtime values of args.
+func (m *tripleMethod) argTypes(args interface{}) (string, error) {
+	if types, ok := m.service.methodTypes[m.name]; ok {
+		return types, nil
+	}
+	return m.service.methodCache.GetTypes(args, m.annotation)
+}
+
+// resultTypes returns the return type of the method, which is derived from IDL if it is provided by
+// WithFileDescriptor, otherwise from the runtime value of result.
+func (m *tripleMethod) resultTypes(result interface{}) (string, error) {
+	if types, ok := m.service.resultTypes[m.name]; ok {
+		return types, nil
+	}
+	return m.service.methodCache.GetTypes(result, nil)
+}
+
+// tripleArgs is the TripleRequestWrapper of the args generated by kitex,
+// each argument is serialized by hessian2 separately.
+type tripleArgs struct {
+	method *tripleMethod
+	data   interface{}
+}
+
+// Marshal implements protobuf.ProtobufMsgCodec.
+func (a *tripleArgs) Marshal(out []byte) ([]byte, error) {
+	data, ok := getMessage(a.data)
+	if !ok {
+		return nil, fmt.Errorf("invalid data: not hessian2.MessageWriter")
+	}
+	types, err := a.method.argTypes(data)
+	if err != nil {
+		return nil, err
+	}
+	out = appendTripleString(out, tripleSerializeTypeField, tripleHessianSerializeType)
+	e := &tripleEncoder{field: tripleRequestArgsField, out: out}
+	if err := data.Encode(e); err != nil {
+		return nil, err
+	}
+	out = e.out
+	for _, typ := range tripleTypeNames(types) {
+		out = appendTripleString(out, tripleRequestArgTypesField, typ)
+	}
+	return out, nil
+}
+
+// Unmarshal implements protobuf.ProtobufMsgCodec.
+func (a *tripleArgs) Unmarshal(in []byte) error {
+	d := new(tripleDecoder)
+	err := consumeTripleFields(in, func(num protowire.Number, b []byte) error {
+		switch num {
+		case tripleSerializeTypeField:
+			return checkTripleSerializeType(string(b))
+		case tripleRequestArgsField:
+			d.values = append(d.values, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data, ok := getMessage(a.data)
+	if !ok {
+		return fmt.Errorf("invalid data: not hessian2.MessageReader")
+	}
+	return data.Decode(d)
+}
+
+// tripleResult is the TripleResponseWrapper of the result generated by kitex.
+// The exception thrown by the method is carried instead of the result if it is set.
+type tripleResult struct {
+	method    *tripleMethod
+	data      interface{}
+	exception hessian2_exception.Throwabler
+}
+
+// Marshal implements protobuf.ProtobufMsgCodec.
+func (r *tripleResult) Marshal(out []byte) ([]byte, error) {
+	if r.exception != nil {
+		out = appendTripleString(out, tripleSerializeTypeField, tripleHessianSerializeType)
+		e := &tripleEncoder{field: tripleResponseDataField, out: out}
+		if err := e.Encode(r.exception); err != nil {
+			return nil, err
+		}
+		return appendTripleString(e.out, tripleResponseTypeField, r.exception.JavaClassName()), nil
+	}
+	data, ok := getMessage(r.data)
+	if !ok {
+		return nil, fmt.Errorf("invalid data: not hessian2.MessageWriter")
+	}
+	types, err := r.method.resultTypes(data)
+	if err != nil {
+		return nil, err
+	}
+	out = appendTripleString(out, tripleSerializeTypeField, tripleHessianSerializeType)
+	e := &tripleEncoder{field: tripleResponseDataField, out: out}
+	if err := data.Encode(e); err != nil {
+		return nil, err
+	}
+	switch e.count {
+	case 0:
+		// void methods reply null
+		if err := e.Encode(nil); err != nil {
+			return nil, err
+		}
+	case 1:
+	default:
+		return nil, fmt.Errorf("triple response could only carry one value, got %d", e.count)
+	}
+	out = e.out
+	if names := tripleTypeNames(types); len(names) != 0 {
+		out = appendTripleString(out, tripleResponseTypeField, names[0])
+	}
+	return out, nil
+}
+
+// Unmarshal implements protobuf.ProtobufMsgCodec.
+func (r *tripleResult) Unmarshal(in []byte) error {
+	d := new(tripleDecoder)
+	err := consumeTripleFields(in, func(num protowire.Number, b []byte) error {
+		switch num {
+		case tripleSerializeTypeField:
+			return checkTripleSerializeType(string(b))
+		case tripleResponseDataField:
+			d.values = [][]byte{b}
+		}
+		return nil
+	})
+	if err != nil || len(d.values) == 0 {
+		return err
+	}
+	// exceptions are recognized by the decoded value since the tri-exception-code trailer arrives after the message
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if exception, ok := v.(hessian2_exception.Throwabler); ok {
+		r.exception = exception
+		return nil
+	}
+	data, ok := getMessage(r.data)
+	if !ok {
+		return fmt.Errorf("invalid data: not hessian2.MessageReader")
+	}
+	return data.Decode(&tripleDecoder{decoded: []interface{}{v}})
+}
+
+// tripleEncoder serializes each value by hessian2 and appends it to out as a field of the triple wrapper.
+type tripleEncoder struct {
+	field protowire.Number
+	out   []byte
+	count int
+}
+
+func (e *tripleEncoder) Encode(v interface{}) error {
+	encoder := hessian2.NewEncoder()
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	e.out = protowire.AppendTag(e.out, e.field, protowire.BytesType)
+	e.out = protowire.AppendBytes(e.out, encoder.Buffer())
+	e.count++
+	return nil
+}
+
+func (e *tripleEncoder) Buffer() []byte {
+	return e.out
+}
+
+// tripleDecoder decodes the values serialized by hessian2 separately in order,
+// the values already decoded are returned first.
+type tripleDecoder struct {
+	decoded []interface{}
+	values  [][]byte
+}
+
+func (d *tripleDecoder) Decode() (interface{}, error) {
+	if len(d.decoded) != 0 {
+		v := d.decoded[0]
+		d.decoded = d.decoded[1:]
+		return v, nil
+	}
+	if len(d.values) == 0 {
+		return nil, errors.New("triple wrapper has no more values")
+	}
+	b := d.values[0]
+	d.values = d.values[1:]
+	return hessian2.NewDecoder(b).Decode()
+}
+
+func checkTripleSerializeType(typ string) error {
+	switch typ {
+	case tripleHessianSerializeType, hessian2.SerializationName:
+		return nil
+	}
+	return fmt.Errorf("unsupported triple serializeType: %s", typ)
+}
+
+func appendTripleString(out []byte, num protowire.Number, s string) []byte {
+	out = protowire.AppendTag(out, num, protowire.BytesType)
+	return protowire.AppendString(out, s)
+}
+
+// consumeTripleFields calls fn with the length-delimited fields of the triple wrapper, other fields are skipped.
+func consumeTripleFields(in []byte, fn func(num protowire.Number, b []byte) error) error {
+	for len(in) > 0 {
+		num, typ, n := protowire.ConsumeTag(in)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		in = in[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, in); n < 0 {
+				return protowire.ParseError(n)
+			}
+			in = in[n:]
+			continue
+		}
+		b, n := protowire.ConsumeBytes(in)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		in = in[n:]
+		if err := fn(num, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tripleTypeNames converts the parameter types in descriptor form to the java class names carried by
+// TripleRequestWrapper, which are the results of Class#getName, e.g.
+// "Ljava/lang/String;[I[Ljava/lang/String;" -> ["java.lang.String", "[I", "[Ljava.lang.String;"].
+func tripleTypeNames(types string) []string {
+	var names []string
+	for i := 0; i < len(types); {
+		start := i
+		for i < len(types) && types[i] == '[' {
+			i++
+		}
+		if i >= len(types) {
+			break
+		}
+		if types[i] == 'L' {
+			if end := strings.IndexByte(types[i:], ';'); end >= 0 {
+				i += end
+			} else {
+				i = len(types) - 1
+			}
+		}
+		i++
+		desc := types[start:i]
+		if desc[0] == '[' {
+			names = append(names, strings.Replace(desc, "/", ".", -1))
+		} else {
+			names = append(names, javaTypeNames(desc)...)
+		}
+	}
+	return names
+}
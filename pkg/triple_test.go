@@ -0,0 +1,412 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/codes"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/metadata"
+	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2/status"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/serviceinfo"
+	"github.com/cloudwego/kitex/server"
+	"github.com/cloudwego/thriftgo/thrift_reflection"
+	"github.com/kitex-contrib/codec-dubbo/pkg/dubbo_spec"
+	"github.com/kitex-contrib/codec-dubbo/pkg/hessian2"
+	hessian2_exception "github.com/kitex-contrib/codec-dubbo/pkg/hessian2/exception"
+	"github.com/kitex-contrib/codec-dubbo/pkg/iface"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The wrappers below are assembled by hand following TripleWrapper.proto of dubbo-java and the hessian2 spec,
+// they are the messages exchanged with dubbo-java for GreetProvider#greet(String, Integer).
+var (
+	tripleGreetRequest = "0a08" + hexOf("hessian4") + // serializeType
+		"1206" + "05" + hexOf("world") + // args[0]: compact string
+		"1201" + "92" + // args[1]: compact int 2
+		"1a10" + hexOf("java.lang.String") + // argTypes[0]
+		"1a11" + hexOf("java.lang.Integer") // argTypes[1]
+	tripleGreetResponse = "0a08" + hexOf("hessian4") + // serializeType
+		"120b" + "0a" + hexOf("worldworld") + // data: compact string
+		"1a10" + hexOf("java.lang.String") // type
+	// the exception is written as an object of java.lang.Exception with only the detailMessage field
+	tripleGreetException = "0a08" + hexOf("hessian4") + // serializeType
+		"1233" + "43" + "13" + hexOf("java.lang.Exception") + "91" + "0d" + hexOf("detailMessage") + // data: class definition
+		"60" + "0d" + hexOf("negative size") + // data: object of the class definition 0
+		"1a13" + hexOf("java.lang.Exception") // type
+)
+
+type tripleTestArgs struct {
+	Req  string
+	Size int32
+}
+
+func (p *tripleTestArgs) Encode(e iface.Encoder) error {
+	if err := e.Encode(p.Req); err != nil {
+		return err
+	}
+	return e.Encode(p.Size)
+}
+
+func (p *tripleTestArgs) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	if err = hessian2.ReflectResponse(v, &p.Req); err != nil {
+		return err
+	}
+	if v, err = d.Decode(); err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Size)
+}
+
+type tripleTestResult struct {
+	Success *string
+}
+
+func (p *tripleTestResult) Encode(e iface.Encoder) error {
+	return e.Encode(p.Success)
+}
+
+func (p *tripleTestResult) Decode(d iface.Decoder) error {
+	v, err := d.Decode()
+	if err != nil {
+		return err
+	}
+	return hessian2.ReflectResponse(v, &p.Success)
+}
+
+func tripleTestHandler(ctx context.Context, handler, arg, result interface{}) error {
+	args := arg.(*tripleTestArgs)
+	if args.Size < 0 {
+		return errors.New("negative size")
+	}
+	resp := ""
+	for i := int32(0); i < args.Size; i++ {
+		resp += args.Req
+	}
+	result.(*tripleTestResult).Success = &resp
+	return nil
+}
+
+var tripleTestServiceInfo = &serviceinfo.ServiceInfo{
+	ServiceName: "GreetService",
+	Methods: map[string]serviceinfo.MethodInfo{
+		"Greet": serviceinfo.NewMethodInfo(tripleTestHandler,
+			func() interface{} { return new(tripleTestArgs) },
+			func() interface{} { return new(tripleTestResult) },
+			false,
+		),
+	},
+	Extra: map[string]interface{}{"PackageName": "hello"},
+}
+
+func TestNewTripleServiceInfo(t *testing.T) {
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName))
+	assert.Equal(t, "GreetProvider", svcInfo.ServiceName)
+	assert.Equal(t, "org.cloudwego.kitex.samples.api", svcInfo.GetPackageName())
+	assert.Equal(t, serviceinfo.Protobuf, svcInfo.PayloadCodec)
+	assert.Equal(t, serviceinfo.StreamingUnary, svcInfo.MethodInfo("Greet").StreamingMode())
+	_, ok := svcInfo.MethodInfo("Greet").NewArgs().(*tripleArgs)
+	assert.True(t, ok)
+
+	// services generated by kitex protobuf are served in the protobuf mode
+	svcInfo = NewTripleServiceInfo(pbServiceInfo, WithJavaClassName("hello.Greeter"))
+	assert.Equal(t, "Greeter", svcInfo.ServiceName)
+	assert.Equal(t, "hello", svcInfo.GetPackageName())
+	assert.Equal(t, serviceinfo.StreamingNone, svcInfo.MethodInfo("Greet").StreamingMode())
+	_, ok = svcInfo.MethodInfo("Greet").NewArgs().(*pbGreetArgs)
+	assert.True(t, ok)
+
+	_, err := NewTripleClient("GreetProvider", tripleTestServiceInfo)
+	assert.NotNil(t, err)
+}
+
+func TestTripleWrapper(t *testing.T) {
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName))
+	methodInfo := svcInfo.MethodInfo("Greet")
+
+	args := &tripleArgs{method: svcInfo.Extra[tripleServiceKey].(*tripleService).methods["Greet"],
+		data: &tripleTestArgs{Req: "world", Size: 2}}
+	buf, err := args.Marshal(nil)
+	assert.Nil(t, err)
+
+	// fields of TripleRequestWrapper
+	var fields []interface{}
+	assert.Nil(t, consumeTripleFields(buf, func(num protowire.Number, b []byte) error {
+		if num == tripleRequestArgsField {
+			v, err := hessian2.NewDecoder(b).Decode()
+			fields = append(fields, v)
+			return err
+		}
+		fields = append(fields, string(b))
+		return nil
+	}))
+	assert.Equal(t, []interface{}{"hessian4", "world", int32(2), "java.lang.String", "java.lang.Integer"}, fields)
+
+	decoded := methodInfo.NewArgs().(*tripleArgs)
+	assert.Nil(t, decoded.Unmarshal(buf))
+	assert.Equal(t, args.data, decoded.data)
+
+	resp := "worldworld"
+	result := &tripleResult{method: args.method, data: &tripleTestResult{Success: &resp}}
+	buf, err = result.Marshal(nil)
+	assert.Nil(t, err)
+	decodedResult := methodInfo.NewResult().(*tripleResult)
+	assert.Nil(t, decodedResult.Unmarshal(buf))
+	assert.Equal(t, result.data, decodedResult.data)
+
+	// hessian2 is also accepted as the serializeType, others are rejected
+	buf = appendTripleString(nil, tripleSerializeTypeField, "hessian2")
+	assert.Nil(t, methodInfo.NewResult().(*tripleResult).Unmarshal(buf))
+	buf = appendTripleString(nil, tripleSerializeTypeField, "fastjson2")
+	assert.NotNil(t, methodInfo.NewArgs().(*tripleArgs).Unmarshal(buf))
+}
+
+func TestTripleWrapperIDLTypes(t *testing.T) {
+	greet := newTestMethodDescriptor("Greet", "", "string", "i64")
+	greet.Response = &thrift_reflection.TypeDescriptor{Name: "string"}
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName),
+		WithFileDescriptor(newTestFileDescriptor(greet)))
+	method := svcInfo.Extra[tripleServiceKey].(*tripleService).methods["Greet"]
+
+	typeNames := func(buf []byte, field protowire.Number) (names []string) {
+		assert.Nil(t, consumeTripleFields(buf, func(num protowire.Number, b []byte) error {
+			if num == field {
+				names = append(names, string(b))
+			}
+			return nil
+		}))
+		return names
+	}
+	// the types specified by IDL instead of the runtime values
+	buf, err := (&tripleArgs{method: method, data: &tripleTestArgs{Req: "world", Size: 2}}).Marshal(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"java.lang.String", "java.lang.Long"}, typeNames(buf, tripleRequestArgTypesField))
+	resp := "worldworld"
+	buf, err = (&tripleResult{method: method, data: &tripleTestResult{Success: &resp}}).Marshal(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"java.lang.String"}, typeNames(buf, tripleResponseTypeField))
+}
+
+func TestTripleWrapperInterop(t *testing.T) {
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName))
+	method := svcInfo.Extra[tripleServiceKey].(*tripleService).methods["Greet"]
+
+	args := &tripleArgs{method: method, data: &tripleTestArgs{Req: "world", Size: 2}}
+	buf, err := args.Marshal(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, tripleGreetRequest, hex.EncodeToString(buf))
+	decodedArgs := &tripleArgs{method: method, data: new(tripleTestArgs)}
+	assert.Nil(t, decodedArgs.Unmarshal(mustDecodeHex(t, tripleGreetRequest)))
+	assert.Equal(t, args.data, decodedArgs.data)
+
+	resp := "worldworld"
+	result := &tripleResult{method: method, data: &tripleTestResult{Success: &resp}}
+	buf, err = result.Marshal(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, tripleGreetResponse, hex.EncodeToString(buf))
+	decodedResult := &tripleResult{method: method, data: new(tripleTestResult)}
+	assert.Nil(t, decodedResult.Unmarshal(mustDecodeHex(t, tripleGreetResponse)))
+	assert.Equal(t, result.data, decodedResult.data)
+	assert.Nil(t, decodedResult.exception)
+
+	decodedResult = &tripleResult{method: method, data: new(tripleTestResult)}
+	assert.Nil(t, decodedResult.Unmarshal(mustDecodeHex(t, tripleGreetException)))
+	assert.Nil(t, decodedResult.data.(*tripleTestResult).Success)
+	assert.Equal(t, "java.lang.Exception", decodedResult.exception.JavaClassName())
+	assert.Equal(t, "negative size", decodedResult.exception.Error())
+
+	// exceptions are written with the class name as the type
+	result = &tripleResult{method: method, data: new(tripleTestResult), exception: decodedResult.exception}
+	buf, err = result.Marshal(nil)
+	assert.Nil(t, err)
+	var fields []interface{}
+	assert.Nil(t, consumeTripleFields(buf, func(num protowire.Number, b []byte) error {
+		if num == tripleResponseDataField {
+			v, err := hessian2.NewDecoder(b).Decode()
+			fields = append(fields, v.(error).Error())
+			return err
+		}
+		fields = append(fields, string(b))
+		return nil
+	}))
+	assert.Equal(t, []interface{}{"hessian4", "negative size", "java.lang.Exception"}, fields)
+}
+
+func TestTripleCheckRequest(t *testing.T) {
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithServiceKeys(map[string]ServiceKey{
+		"GreetService": {InterfaceName: testJavaClassName, Group: "g1", Version: "1.0.0"},
+	}))
+	ts := svcInfo.Extra[tripleServiceKey].(*tripleService)
+
+	tests := []struct {
+		desc     string
+		pkg      string
+		headers  []string
+		expected codes.Code
+	}{
+		{
+			desc:     "matched",
+			pkg:      "org.cloudwego.kitex.samples.api",
+			headers:  []string{tripleServiceGroupHeader, "g1", tripleServiceVersionHeader, "1.0.0"},
+			expected: codes.OK,
+		},
+		{
+			desc:     "other version",
+			pkg:      "org.cloudwego.kitex.samples.api",
+			headers:  []string{tripleServiceGroupHeader, "g1", tripleServiceVersionHeader, "2.0.0"},
+			expected: codes.Unimplemented,
+		},
+		{
+			desc:     "default group",
+			pkg:      "org.cloudwego.kitex.samples.api",
+			headers:  []string{tripleServiceVersionHeader, "1.0.0"},
+			expected: codes.Unimplemented,
+		},
+		{
+			desc:     "other package",
+			pkg:      "hello",
+			headers:  []string{tripleServiceGroupHeader, "g1", tripleServiceVersionHeader, "1.0.0"},
+			expected: codes.Unimplemented,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ink := rpcinfo.NewServerInvocation()
+			ink.(rpcinfo.InvocationSetter).SetPackageName(test.pkg)
+			ctx := rpcinfo.NewCtxWithRPCInfo(context.Background(), rpcinfo.NewRPCInfo(nil, nil, ink, nil, nil))
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(test.headers...))
+			assert.Equal(t, test.expected, status.Code(ts.checkRequest(ctx)))
+		})
+	}
+}
+
+func TestConvertTripleError(t *testing.T) {
+	var statusErr *DubboStatusError
+	err := convertTripleError(status.Err(codes.Unimplemented, "unknown service"))
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, dubbo_spec.StatusServiceNotFound, statusErr.Status)
+	assert.Equal(t, "unknown service", statusErr.Message)
+
+	err = convertTripleError(status.Err(codes.DeadlineExceeded, "timeout"))
+	assert.True(t, errors.Is(err, kerrors.ErrRPCTimeout))
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, dubbo_spec.StatusClientTimeout, statusErr.Status)
+
+	assert.Nil(t, convertTripleError(nil))
+}
+
+func TestTripleTypeNames(t *testing.T) {
+	assert.Equal(t, []string{"java.lang.String", "int", "[I", "[Ljava.lang.String;", "[[J", "boolean"},
+		tripleTypeNames("Ljava/lang/String;I[I[Ljava/lang/String;[[JZ"))
+	assert.Empty(t, tripleTypeNames(""))
+}
+
+func TestTripleInvoke(t *testing.T) {
+	t.Run("stream", func(t *testing.T) {
+		testTripleInvoke(t)
+	})
+	// the unwrapped args are passed to the handler directly
+	t.Run("compatible middleware for unary", func(t *testing.T) {
+		testTripleInvoke(t, server.WithCompatibleMiddlewareForUnary())
+	})
+}
+
+func runTripleServer(t *testing.T, svcInfo *serviceinfo.ServiceInfo, opts ...server.Option) (string, server.Server) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	assert.Nil(t, ln.Close())
+
+	opts = append(opts, server.WithServiceAddr(addr), server.WithExitWaitTime(time.Millisecond))
+	svr := server.NewServer(opts...)
+	assert.Nil(t, svr.RegisterService(svcInfo, new(struct{})))
+	go svr.Run()
+	return addr.String(), svr
+}
+
+// callTriple retries the call until the server is listening.
+func callTriple(cli client.Client, args *tripleTestArgs, result *tripleTestResult) (err error) {
+	for i := 0; i < 50; i++ {
+		err = cli.Call(context.Background(), "Greet", args, result)
+		if !errors.Is(err, kerrors.ErrGetConnection) {
+			return err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return err
+}
+
+func testTripleInvoke(t *testing.T, opts ...server.Option) {
+	svcInfo := NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName))
+	addr, svr := runTripleServer(t, svcInfo, opts...)
+	defer svr.Stop()
+
+	cli, err := NewTripleClient("GreetProvider", svcInfo, client.WithHostPorts(addr))
+	assert.Nil(t, err)
+
+	var result tripleTestResult
+	assert.Nil(t, callTriple(cli, &tripleTestArgs{Req: "world", Size: 2}, &result))
+	assert.Equal(t, "worldworld", *result.Success)
+
+	// the error of the handler is carried as the exception thrown by the method
+	err = callTriple(cli, &tripleTestArgs{Req: "world", Size: -1}, new(tripleTestResult))
+	exception, ok := hessian2_exception.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "java.lang.Exception", exception.JavaClassName())
+	assert.Equal(t, "negative size", exception.Error())
+}
+
+func TestTripleInvokeServiceKey(t *testing.T) {
+	keyOpt := WithServiceKeys(map[string]ServiceKey{
+		"GreetService": {InterfaceName: testJavaClassName, Group: "g1", Version: "1.0.0"},
+	})
+	addr, svr := runTripleServer(t, NewTripleServiceInfo(tripleTestServiceInfo, keyOpt))
+	defer svr.Stop()
+
+	cli, err := NewTripleClient("GreetProvider", NewTripleServiceInfo(tripleTestServiceInfo, keyOpt),
+		client.WithHostPorts(addr))
+	assert.Nil(t, err)
+	var result tripleTestResult
+	assert.Nil(t, callTriple(cli, &tripleTestArgs{Req: "world", Size: 1}, &result))
+	assert.Equal(t, "world", *result.Success)
+
+	// requests of the default group and version are rejected
+	cli, err = NewTripleClient("GreetProvider",
+		NewTripleServiceInfo(tripleTestServiceInfo, WithJavaClassName(testJavaClassName)),
+		client.WithHostPorts(addr))
+	assert.Nil(t, err)
+	err = callTriple(cli, &tripleTestArgs{Req: "world", Size: 1}, new(tripleTestResult))
+	var statusErr *DubboStatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, dubbo_spec.StatusServiceNotFound, statusErr.Status)
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registries
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/discovery"
+)
+
+// RegisterMode specifies how services are registered and discovered, which is referred to
+// ApplicationConfig.registerMode of dubbo.
+const (
+	// RegisterModeInterface registers and discovers each interface under /dubbo/<interface>/providers.
+	RegisterModeInterface = "interface"
+	// RegisterModeInstance registers and discovers application instances under /services/<app>/<ip:port>.
+	RegisterModeInstance = "instance"
+	// RegisterModeAll registers both of them. Resolvers discover instances firstly and fall back to
+	// interfaces if no instance exports the interface.
+	RegisterModeAll = "all"
+)
+
+const (
+	// ServiceInstancesKeyTemplate is the path of the instances of an application.
+	ServiceInstancesKeyTemplate = "/services/%s"
+	// ServiceNameMappingKeyTemplate is the path of the applications exporting an interface,
+	// the content is the application names separated by commas.
+	ServiceNameMappingKeyTemplate = "/%s/mapping/%s"
+	// MetadataInfoKeyTemplate is the path of the MetadataInfo of an application revision.
+	MetadataInfoKeyTemplate = "/%s/metadata/%s/%s"
+
+	metadataRevisionKey    = "dubbo.metadata.revision"
+	metadataStorageTypeKey = "dubbo.metadata.storage-type"
+	endpointsKey           = "dubbo.endpoints"
+
+	// metadataStorageTypeRemote asks consumers to fetch MetadataInfo from the metadata report
+	// instead of invoking the MetadataService of the provider.
+	metadataStorageTypeRemote = "remote"
+
+	zookeeperInstanceClass = "org.apache.dubbo.registry.zookeeper.ZookeeperInstance"
+)
+
+var errMissingApplication = errors.New("tags must contain DubboServiceApplicationKey:<applicationName> pair to register instance")
+
+// MetadataInfo describes the services exported by the instances of an application revision,
+// which is compatible with org.apache.dubbo.metadata.MetadataInfo.
+type MetadataInfo struct {
+	App      string                  `json:"app"`
+	Revision string                  `json:"revision"`
+	Services map[string]*ServiceInfo `json:"services"`
+}
+
+// ServiceInfo describes a service exported by the application.
+type ServiceInfo struct {
+	Name     string            `json:"name"`
+	Group    string            `json:"group,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Protocol string            `json:"protocol"`
+	Port     int               `json:"port,omitempty"`
+	Path     string            `json:"path"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// NewMetadataInfo creates an empty MetadataInfo of app.
+func NewMetadataInfo(app string) *MetadataInfo {
+	return &MetadataInfo{
+		App:      app,
+		Services: make(map[string]*ServiceInfo),
+	}
+}
+
+// AddService adds s and updates the revision.
+func (m *MetadataInfo) AddService(s *ServiceInfo) {
+	m.Services[s.MatchKey()] = s
+	m.Revision = m.calRevision()
+}
+
+// RemoveService removes s and updates the revision.
+func (m *MetadataInfo) RemoveService(s *ServiceInfo) {
+	delete(m.Services, s.MatchKey())
+	m.Revision = m.calRevision()
+}
+
+// GetService returns the service of interfaceName with group and version served by protocol, nil if not exported.
+func (m *MetadataInfo) GetService(interfaceName, group, version, protocol string) *ServiceInfo {
+	for _, s := range m.Services {
+		if s.Name == interfaceName && s.Group == group && s.Version == version && s.Protocol == protocol {
+			return s
+		}
+	}
+	return nil
+}
+
+// calRevision calculates the md5 of the app and the services, which changes with the exported services.
+func (m *MetadataInfo) calRevision() string {
+	keys := make([]string, 0, len(m.Services))
+	for key := range m.Services {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(m.App)
+	for _, key := range keys {
+		sb.WriteString(m.Services[key].descString())
+	}
+	sum := md5.Sum([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchKey returns the key of the service in MetadataInfo, e.g. group/interface:version:protocol.
+func (s *ServiceInfo) MatchKey() string {
+	key := s.Name
+	if s.Group != "" {
+		key = s.Group + "/" + key
+	}
+	if s.Version != "" {
+		key += ":" + s.Version
+	}
+	return key + ":" + s.Protocol
+}
+
+func (s *ServiceInfo) descString() string {
+	keys := make([]string, 0, len(s.Params))
+	for key := range s.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := make([]string, len(keys))
+	for i, key := range keys {
+		params[i] = key + "=" + s.Params[key]
+	}
+	return fmt.Sprintf("%s%d%s{%s}", s.MatchKey(), s.Port, s.Path, strings.Join(params, ", "))
+}
+
+// ServiceInstance is an instance of application registered under /services/<app>/<ip:port>,
+// which is compatible with the ServiceInstance of curator-x-discovery used by dubbo.
+type ServiceInstance struct {
+	Name                string           `json:"name"`
+	ID                  string           `json:"id"`
+	Address             string           `json:"address"`
+	Port                int              `json:"port"`
+	SSLPort             *int             `json:"sslPort"`
+	Payload             *InstancePayload `json:"payload"`
+	RegistrationTimeUTC int64            `json:"registrationTimeUTC"`
+	ServiceType         string           `json:"serviceType"`
+	URISpec             interface{}      `json:"uriSpec"`
+}
+
+// InstancePayload carries the metadata of the instance.
+type InstancePayload struct {
+	Class    string            `json:"@class"`
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type endpoint struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// NewServiceInstance creates the instance serving the services of metadata at addr, whose MetadataInfo should be
+// published to the metadata report.
+func NewServiceInstance(addr string, metadata *MetadataInfo) (*ServiceInstance, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := json.Marshal([]endpoint{{Port: port, Protocol: DefaultProtocol}})
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceInstance{
+		Name:    metadata.App,
+		ID:      addr,
+		Address: host,
+		Port:    port,
+		Payload: &InstancePayload{
+			Class: zookeeperInstanceClass,
+			ID:    addr,
+			Name:  metadata.App,
+			Metadata: map[string]string{
+				metadataRevisionKey:    metadata.Revision,
+				metadataStorageTypeKey: metadataStorageTypeRemote,
+				endpointsKey:           string(endpoints),
+			},
+		},
+		RegistrationTimeUTC: time.Now().UnixNano() / int64(time.Millisecond),
+		ServiceType:         "DYNAMIC",
+	}, nil
+}
+
+// Revision returns the revision of the MetadataInfo of the instance.
+func (i *ServiceInstance) Revision() string {
+	if i.Payload == nil {
+		return ""
+	}
+	return i.Payload.Metadata[metadataRevisionKey]
+}
+
+// ToInstance converts the instance exporting s to kitex Instance. The port of the instance is the one
+// serving the protocol of s, which is specified by dubbo.endpoints or s.
+func (i *ServiceInstance) ToInstance(s *ServiceInfo) discovery.Instance {
+	port := i.Port
+	if s.Port != 0 {
+		port = s.Port
+	}
+	if i.Payload != nil {
+		var endpoints []endpoint
+		if err := json.Unmarshal([]byte(i.Payload.Metadata[endpointsKey]), &endpoints); err == nil {
+			for _, e := range endpoints {
+				if e.Protocol == s.Protocol {
+					port = e.Port
+					break
+				}
+			}
+		}
+	}
+	weight := DefaultDubboServiceWeight
+	if weightParam, err := strconv.Atoi(s.Params[dubboInternalWeightKey]); err == nil {
+		weight = weightParam
+	}
+	params := map[string]string{
+		DubboServiceGroupKey:       s.Group,
+		DubboServiceVersionKey:     s.Version,
+		DubboServiceApplicationKey: i.Name,
+	}
+	return discovery.NewInstance("tcp", net.JoinHostPort(i.Address, strconv.Itoa(port)), weight, params)
+}
+
+// ParseServiceNameMapping returns the application names in the content of the service name mapping.
+func ParseServiceNameMapping(content string) []string {
+	var apps []string
+	for _, app := range strings.Split(content, ",") {
+		if app = strings.TrimSpace(app); app != "" {
+			apps = append(apps, app)
+		}
+	}
+	return apps
+}
+
+// Application returns the application name of the service, which is specified by DubboServiceApplicationKey.
+func (u *URL) Application() (string, error) {
+	app := u.params.Get(dubboInternalApplicationKey)
+	if app == "" {
+		return "", errMissingApplication
+	}
+	return app, nil
+}
+
+// InterfaceName returns the interface name of the service.
+func (u *URL) InterfaceName() string {
+	return u.interfaceName
+}
+
+// Host returns the address of the service in the form of ip:port.
+func (u *URL) Host() string {
+	return u.host
+}
+
+// ToServiceInfo converts the URL to the ServiceInfo in MetadataInfo.
+func (u *URL) ToServiceInfo() *ServiceInfo {
+	s := &ServiceInfo{
+		Name:     u.interfaceName,
+		Group:    u.params.Get(dubboInternalGroupKey),
+		Version:  u.params.Get(dubboInternalVersionKey),
+		Protocol: u.protocol,
+		Path:     u.interfaceName,
+		Params:   make(map[string]string, len(u.params)),
+	}
+	if _, port, err := net.SplitHostPort(u.host); err == nil {
+		s.Port, _ = strconv.Atoi(port)
+	}
+	for key := range u.params {
+		s.Params[key] = u.params.Get(key)
+	}
+	return s
+}
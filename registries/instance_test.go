@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registries
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataInfo(t *testing.T) {
+	greet := &ServiceInfo{Name: "org.cloudwego.kitex.samples.api.GreetProvider", Protocol: DefaultProtocol, Port: 20000}
+	echo := &ServiceInfo{Name: "org.cloudwego.kitex.samples.api.EchoProvider", Group: "g1", Version: "1.0.0", Protocol: DefaultProtocol}
+	assert.Equal(t, "org.cloudwego.kitex.samples.api.GreetProvider:dubbo", greet.MatchKey())
+	assert.Equal(t, "g1/org.cloudwego.kitex.samples.api.EchoProvider:1.0.0:dubbo", echo.MatchKey())
+
+	m := NewMetadataInfo("demo")
+	m.AddService(greet)
+	greetRevision := m.Revision
+	assert.NotEmpty(t, greetRevision)
+	m.AddService(echo)
+	assert.NotEqual(t, greetRevision, m.Revision)
+	assert.Equal(t, echo, m.GetService(echo.Name, "g1", "1.0.0", DefaultProtocol))
+	assert.Nil(t, m.GetService(echo.Name, "", "1.0.0", DefaultProtocol))
+	assert.Nil(t, m.GetService(echo.Name, "g1", "1.0.0", "tri"))
+
+	// the revision only depends on the exported services
+	m.RemoveService(echo)
+	assert.Equal(t, greetRevision, m.Revision)
+	other := NewMetadataInfo("demo")
+	other.AddService(greet)
+	assert.Equal(t, greetRevision, other.Revision)
+}
+
+func TestServiceInstance(t *testing.T) {
+	m := NewMetadataInfo("demo")
+	s := &ServiceInfo{
+		Name:     "org.cloudwego.kitex.samples.api.GreetProvider",
+		Version:  "1.0.0",
+		Protocol: DefaultProtocol,
+		Params:   map[string]string{dubboInternalWeightKey: "50"},
+	}
+	m.AddService(s)
+	instance, err := NewServiceInstance("127.0.0.1:20000", m)
+	assert.Nil(t, err)
+
+	buf, err := json.Marshal(instance)
+	assert.Nil(t, err)
+	decoded := new(ServiceInstance)
+	assert.Nil(t, json.Unmarshal(buf, decoded))
+	assert.Equal(t, m.Revision, decoded.Revision())
+	assert.Equal(t, "127.0.0.1", decoded.Address)
+	assert.Equal(t, zookeeperInstanceClass, decoded.Payload.Class)
+
+	ins := decoded.ToInstance(s)
+	assert.Equal(t, "127.0.0.1:20000", ins.Address().String())
+	assert.Equal(t, 50, ins.Weight())
+	version, _ := ins.Tag(DubboServiceVersionKey)
+	assert.Equal(t, "1.0.0", version)
+	app, _ := ins.Tag(DubboServiceApplicationKey)
+	assert.Equal(t, "demo", app)
+
+	// the port serving the protocol is specified by dubbo.endpoints
+	decoded.Payload.Metadata[endpointsKey] = `[{"port":20001,"protocol":"tri"},{"port":20002,"protocol":"dubbo"}]`
+	assert.Equal(t, "127.0.0.1:20002", decoded.ToInstance(s).Address().String())
+
+	_, err = NewServiceInstance("127.0.0.1", m)
+	assert.NotNil(t, err)
+}
+
+func TestParseServiceNameMapping(t *testing.T) {
+	assert.Equal(t, []string{"app1", "app2"}, ParseServiceNameMapping("app1, app2,"))
+	assert.Empty(t, ParseServiceNameMapping(""))
+}
+
+func TestURL_ToServiceInfo(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:20000")
+	u := new(URL)
+	err := u.FromInfo(&registry.Info{
+		Addr: addr,
+		Tags: map[string]string{
+			DubboServiceInterfaceKey: "org.cloudwego.kitex.samples.api.GreetProvider",
+			DubboServiceGroupKey:     "g1",
+		},
+	})
+	assert.Nil(t, err)
+	_, err = u.Application()
+	assert.Equal(t, errMissingApplication, err)
+
+	s := u.ToServiceInfo()
+	assert.Equal(t, "g1/org.cloudwego.kitex.samples.api.GreetProvider:dubbo", s.MatchKey())
+	assert.Equal(t, 20000, s.Port)
+	assert.Equal(t, "g1", s.Params[dubboInternalGroupKey])
+
+	u.params.Set(dubboInternalApplicationKey, "demo")
+	app, err := u.Application()
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", app)
+}
@@ -3,16 +3,16 @@ module github.com/kitex-contrib/codec-dubbo/registries/zookeeper
 go 1.20
 
 require (
-	github.com/cloudwego/kitex v0.8.0
+	github.com/cloudwego/kitex v0.9.0
 	github.com/go-zookeeper/zk v1.0.3
-	github.com/kitex-contrib/codec-dubbo v0.2.1
+	github.com/kitex-contrib/codec-dubbo v0.2.6-0.20261016184828-c1bd659053c6
 	github.com/stretchr/testify v1.8.2
 )
 
 require (
 	github.com/apache/thrift v0.13.0 // indirect
 	github.com/bytedance/gopkg v0.0.0-20230728082804-614d0af6619b // indirect
-	github.com/choleraehyq/pid v0.0.17 // indirect
+	github.com/choleraehyq/pid v0.0.18 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/google/pprof v0.0.0-20220608213341-c488b8fa1db3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+)
+
+// registerInstance registers the instance serving u under /services/<app>/<ip:port> for the application-level
+// service discovery of dubbo 3. The MetadataInfo of the instance is published to /dubbo/metadata/<app>/<revision>
+// and the application is appended to the service name mapping /dubbo/mapping/<interface>.
+func (z *zookeeperRegistry) registerInstance(u *registries.URL) error {
+	app, err := u.Application()
+	if err != nil {
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	path := fmt.Sprintf(registries.ServiceInstancesKeyTemplate, app) + "/" + u.Host()
+	metadata, ok := z.instances[path]
+	if !ok {
+		metadata = registries.NewMetadataInfo(app)
+	}
+	metadata.AddService(u.ToServiceInfo())
+	if err := z.publishInstance(path, u.Host(), metadata); err != nil {
+		metadata.RemoveService(u.ToServiceInfo())
+		return err
+	}
+	z.instances[path] = metadata
+	return z.registerServiceNameMapping(u.InterfaceName(), app)
+}
+
+// deregisterInstance removes the service of u from the instance, the instance is deregistered
+// if it exports no service.
+func (z *zookeeperRegistry) deregisterInstance(u *registries.URL) error {
+	app, err := u.Application()
+	if err != nil {
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	path := fmt.Sprintf(registries.ServiceInstancesKeyTemplate, app) + "/" + u.Host()
+	metadata, ok := z.instances[path]
+	if !ok {
+		return nil
+	}
+	metadata.RemoveService(u.ToServiceInfo())
+	if len(metadata.Services) != 0 {
+		return z.publishInstance(path, u.Host(), metadata)
+	}
+	delete(z.instances, path)
+	if cancel, ok := z.canceler.remove(path); ok {
+		cancel()
+	}
+	return z.deleteNode(path)
+}
+
+// publishInstance publishes the MetadataInfo and then registers the instance referring to its revision.
+func (z *zookeeperRegistry) publishInstance(path, addr string, metadata *registries.MetadataInfo) error {
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	metadataPath := fmt.Sprintf(registries.MetadataInfoKeyTemplate, z.opt.RegistryGroup, metadata.App, metadata.Revision)
+	// the content of a revision never changes
+	if err := z.createNode(metadataPath, content, false); err != nil && !errors.Is(err, zk.ErrNodeExists) {
+		return err
+	}
+
+	instance, err := registries.NewServiceInstance(addr, metadata)
+	if err != nil {
+		return err
+	}
+	if content, err = json.Marshal(instance); err != nil {
+		return err
+	}
+	if err := z.createNode(path, content, true); err != nil {
+		return err
+	}
+	z.startKeepalive(path, content)
+	return nil
+}
+
+// registerServiceNameMapping appends app to the applications exporting interfaceName,
+// the content is updated by compare-and-set since the mapping is shared by applications.
+func (z *zookeeperRegistry) registerServiceNameMapping(interfaceName, app string) error {
+	path := fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, z.opt.RegistryGroup, interfaceName)
+	for {
+		content, stat, err := z.conn.Get(path)
+		if errors.Is(err, zk.ErrNoNode) {
+			// the node may be created by others concurrently, check the content again
+			if err = z.createNode(path, []byte(app), false); err != nil && !errors.Is(err, zk.ErrNodeExists) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		apps := registries.ParseServiceNameMapping(string(content))
+		for _, mapped := range apps {
+			if mapped == app {
+				return nil
+			}
+		}
+		apps = append(apps, app)
+		_, err = z.conn.Set(path, []byte(strings.Join(apps, ",")), stat.Version)
+		if errors.Is(err, zk.ErrBadVersion) {
+			continue
+		}
+		return err
+	}
+}
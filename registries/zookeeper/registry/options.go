@@ -20,6 +20,7 @@
 package registry
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kitex-contrib/codec-dubbo/registries"
@@ -31,6 +32,7 @@ type Options struct {
 	Username       string
 	Password       string
 	SessionTimeout time.Duration
+	RegisterMode   string
 }
 
 func (o *Options) Apply(opts []Option) {
@@ -54,6 +56,13 @@ func newOptions(opts []Option) *Options {
 	if o.SessionTimeout == 0 {
 		o.SessionTimeout = defaultSessionTimeout
 	}
+	switch o.RegisterMode {
+	case "":
+		o.RegisterMode = registries.RegisterModeInterface
+	case registries.RegisterModeInterface, registries.RegisterModeInstance, registries.RegisterModeAll:
+	default:
+		panic(fmt.Sprintf("Unsupported RegisterMode %s, it should be one of interface, instance and all.", o.RegisterMode))
+	}
 	return o
 }
 
@@ -105,3 +114,14 @@ func WithPassword(password string) Option {
 		o.Password = password
 	}}
 }
+
+// WithRegisterMode configures how services are registered, which is referred to ApplicationConfig.registerMode of dubbo.
+// registries.RegisterModeInterface registers interfaces under /dubbo/<interface>/providers, which is the default mode.
+// registries.RegisterModeInstance registers application instances under /services/<app>/<ip:port> for
+// application-level service discovery of dubbo 3, DubboServiceApplicationKey should be specified in the registry.Info tags.
+// registries.RegisterModeAll registers both of them.
+func WithRegisterMode(mode string) Option {
+	return Option{F: func(o *Options) {
+		o.RegisterMode = mode
+	}}
+}
@@ -36,10 +36,24 @@ const (
 	defaultSessionTimeout = 30 * time.Second
 )
 
+// zkConn is the subset of *zk.Conn used by the registry.
+type zkConn interface {
+	Exists(path string) (bool, *zk.Stat, error)
+	Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error)
+	Delete(path string, version int32) error
+	Get(path string) ([]byte, *zk.Stat, error)
+	Set(path string, data []byte, version int32) (*zk.Stat, error)
+	SessionID() int64
+}
+
 type zookeeperRegistry struct {
-	conn     *zk.Conn
+	conn     zkConn
 	opt      *Options
 	canceler *canceler
+
+	mu sync.Mutex
+	// key: zookeeper path of the instance, val: MetadataInfo of the services exported by the instance
+	instances map[string]*registries.MetadataInfo
 }
 
 func NewZookeeperRegistry(opts ...Option) (registry.Registry, error) {
@@ -62,9 +76,10 @@ func NewZookeeperRegistry(opts ...Option) (registry.Registry, error) {
 		case event := <-eventChan:
 			if event.State == zk.StateConnected {
 				return &zookeeperRegistry{
-					conn:     conn,
-					opt:      o,
-					canceler: newCanceler(),
+					conn:      conn,
+					opt:       o,
+					canceler:  newCanceler(),
+					instances: make(map[string]*registries.MetadataInfo),
 				}, nil
 			}
 		case <-ticker.C:
@@ -81,18 +96,40 @@ func (z *zookeeperRegistry) Register(info *registry.Info) error {
 	if err := u.FromInfo(info); err != nil {
 		return err
 	}
+	if z.opt.RegisterMode != registries.RegisterModeInstance {
+		if err := z.registerInterface(u); err != nil {
+			return err
+		}
+	}
+	if z.opt.RegisterMode != registries.RegisterModeInterface {
+		return z.registerInstance(u)
+	}
+	return nil
+}
+
+func (z *zookeeperRegistry) registerInterface(u *registries.URL) error {
 	path := u.GetRegistryServiceKey(z.opt.RegistryGroup)
 	content := u.ToString()
 	finalPath := path + "/" + content
 	if err := z.createNode(finalPath, nil, true); err != nil {
 		return err
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	z.canceler.add(finalPath, cancel)
-	go z.keepalive(ctx, finalPath, nil)
+	z.startKeepalive(finalPath, nil)
 	return nil
 }
 
+// startKeepalive recreates the ephemeral node with content after the session is reestablished,
+// the keepalive started before for the path is stopped.
+// The session is read before the goroutine starts so that the expiration right after the creation is not missed.
+func (z *zookeeperRegistry) startKeepalive(path string, content []byte) {
+	if cancel, ok := z.canceler.remove(path); ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	z.canceler.add(path, cancel)
+	go z.keepalive(ctx, path, content, z.conn.SessionID())
+}
+
 func (z *zookeeperRegistry) createNode(path string, content []byte, ephemeral bool) error {
 	exists, stat, err := z.conn.Exists(path)
 	if err != nil {
@@ -132,8 +169,7 @@ func (z *zookeeperRegistry) createNode(path string, content []byte, ephemeral bo
 	return nil
 }
 
-func (z *zookeeperRegistry) keepalive(ctx context.Context, path string, content []byte) {
-	sessionID := z.conn.SessionID()
+func (z *zookeeperRegistry) keepalive(ctx context.Context, path string, content []byte, sessionID int64) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 	for {
@@ -159,6 +195,18 @@ func (z *zookeeperRegistry) Deregister(info *registry.Info) error {
 	if err := u.FromInfo(info); err != nil {
 		return err
 	}
+	if z.opt.RegisterMode != registries.RegisterModeInstance {
+		if err := z.deregisterInterface(u); err != nil {
+			return err
+		}
+	}
+	if z.opt.RegisterMode != registries.RegisterModeInterface {
+		return z.deregisterInstance(u)
+	}
+	return nil
+}
+
+func (z *zookeeperRegistry) deregisterInterface(u *registries.URL) error {
 	path := u.GetRegistryServiceKey(z.opt.RegistryGroup)
 	content := u.ToString()
 	finalPath := path + "/" + content
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/pkg/registry"
+	"github.com/go-zookeeper/zk"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInterfaceName = "org.cloudwego.kitex.samples.api.GreetProvider"
+
+type fakeNode struct {
+	data      []byte
+	version   int32
+	ephemeral bool
+}
+
+// fakeConn serves the zookeeper nodes in memory.
+type fakeConn struct {
+	mu        sync.Mutex
+	nodes     map[string]*fakeNode
+	sessionID int64
+	// beforeSet is called before the data of the node is set, e.g. to update the node concurrently.
+	beforeSet func(path string)
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{nodes: make(map[string]*fakeNode), sessionID: 1}
+}
+
+func (c *fakeConn) Exists(path string) (bool, *zk.Stat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[path]
+	if !ok {
+		return false, nil, nil
+	}
+	return true, &zk.Stat{Version: node.version}, nil
+}
+
+func (c *fakeConn) Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.nodes[path]; ok {
+		return "", zk.ErrNodeExists
+	}
+	c.nodes[path] = &fakeNode{data: data, ephemeral: flags&zk.FlagEphemeral != 0}
+	return path, nil
+}
+
+func (c *fakeConn) Delete(path string, version int32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[path]
+	if !ok {
+		return zk.ErrNoNode
+	}
+	if version != -1 && version != node.version {
+		return zk.ErrBadVersion
+	}
+	delete(c.nodes, path)
+	return nil
+}
+
+func (c *fakeConn) Get(path string) ([]byte, *zk.Stat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[path]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return node.data, &zk.Stat{Version: node.version}, nil
+}
+
+func (c *fakeConn) Set(path string, data []byte, version int32) (*zk.Stat, error) {
+	if c.beforeSet != nil {
+		c.beforeSet(path)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[path]
+	if !ok {
+		return nil, zk.ErrNoNode
+	}
+	if version != -1 && version != node.version {
+		return nil, zk.ErrBadVersion
+	}
+	node.data = data
+	node.version++
+	return &zk.Stat{Version: node.version}, nil
+}
+
+func (c *fakeConn) SessionID() int64 {
+	return atomic.LoadInt64(&c.sessionID)
+}
+
+// expireSession drops the ephemeral nodes and starts a new session.
+func (c *fakeConn) expireSession() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path, node := range c.nodes {
+		if node.ephemeral {
+			delete(c.nodes, path)
+		}
+	}
+	atomic.AddInt64(&c.sessionID, 1)
+}
+
+func (c *fakeConn) data(path string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node, ok := c.nodes[path]
+	if !ok {
+		return nil, false
+	}
+	return node.data, true
+}
+
+func newTestRegistry(conn *fakeConn, opts ...Option) *zookeeperRegistry {
+	opts = append([]Option{WithServers("127.0.0.1:2181")}, opts...)
+	return &zookeeperRegistry{
+		conn:      conn,
+		opt:       newOptions(opts),
+		canceler:  newCanceler(),
+		instances: make(map[string]*registries.MetadataInfo),
+	}
+}
+
+func newTestInfo(t *testing.T, addr string, tags map[string]string) *registry.Info {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	assert.Nil(t, err)
+	return &registry.Info{Addr: tcpAddr, Tags: tags}
+}
+
+func TestRegisterServiceNameMapping(t *testing.T) {
+	conn := newFakeConn()
+	z := newTestRegistry(conn)
+	path := fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, registries.DefaultRegistryGroup, testInterfaceName)
+
+	assert.Nil(t, z.registerServiceNameMapping(testInterfaceName, "app1"))
+	data, _ := conn.data(path)
+	assert.Equal(t, "app1", string(data))
+
+	assert.Nil(t, z.registerServiceNameMapping(testInterfaceName, "app2"))
+	assert.Nil(t, z.registerServiceNameMapping(testInterfaceName, "app1"))
+	data, _ = conn.data(path)
+	assert.Equal(t, "app1,app2", string(data))
+
+	// the mapping updated concurrently by other applications is kept
+	updated := false
+	conn.beforeSet = func(p string) {
+		if p == path && !updated {
+			updated = true
+			_, err := conn.Set(path, []byte("app1,app2,app3"), -1)
+			assert.Nil(t, err)
+		}
+	}
+	assert.Nil(t, z.registerServiceNameMapping(testInterfaceName, "app4"))
+	data, _ = conn.data(path)
+	assert.Equal(t, "app1,app2,app3,app4", string(data))
+}
+
+func TestRegisterInstance(t *testing.T) {
+	conn := newFakeConn()
+	z := newTestRegistry(conn, WithRegisterMode(registries.RegisterModeInstance))
+	tags := map[string]string{
+		registries.DubboServiceInterfaceKey:   testInterfaceName,
+		registries.DubboServiceApplicationKey: "app1",
+	}
+	v1 := newTestInfo(t, "127.0.0.1:20000", tags)
+	v2 := newTestInfo(t, "127.0.0.1:20000", map[string]string{
+		registries.DubboServiceInterfaceKey:   testInterfaceName,
+		registries.DubboServiceApplicationKey: "app1",
+		registries.DubboServiceVersionKey:     "2.0.0",
+	})
+	instancePath := fmt.Sprintf(registries.ServiceInstancesKeyTemplate, "app1") + "/127.0.0.1:20000"
+
+	getMetadataInfo := func() *registries.MetadataInfo {
+		data, ok := conn.data(instancePath)
+		assert.True(t, ok)
+		instance := new(registries.ServiceInstance)
+		assert.Nil(t, json.Unmarshal(data, instance))
+		data, ok = conn.data(fmt.Sprintf(registries.MetadataInfoKeyTemplate, registries.DefaultRegistryGroup, "app1", instance.Revision()))
+		assert.True(t, ok)
+		metadata := new(registries.MetadataInfo)
+		assert.Nil(t, json.Unmarshal(data, metadata))
+		return metadata
+	}
+
+	assert.Nil(t, z.Register(v1))
+	assert.Nil(t, z.Register(v2))
+	metadata := getMetadataInfo()
+	assert.Equal(t, 2, len(metadata.Services))
+	assert.NotNil(t, metadata.GetService(testInterfaceName, "", "", registries.DefaultProtocol))
+	assert.NotNil(t, metadata.GetService(testInterfaceName, "", "2.0.0", registries.DefaultProtocol))
+	data, _ := conn.data(fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, registries.DefaultRegistryGroup, testInterfaceName))
+	assert.Equal(t, "app1", string(data))
+	// interfaces are not registered in the instance mode
+	_, ok := conn.data(fmt.Sprintf(registries.RegistryServicesKeyTemplate, registries.DefaultRegistryGroup, testInterfaceName))
+	assert.False(t, ok)
+
+	// the instance refers to the new revision after a service is deregistered
+	assert.Nil(t, z.Deregister(v2))
+	metadata = getMetadataInfo()
+	assert.Equal(t, 1, len(metadata.Services))
+	assert.NotNil(t, metadata.GetService(testInterfaceName, "", "", registries.DefaultProtocol))
+
+	assert.Nil(t, z.Deregister(v1))
+	_, ok = conn.data(instancePath)
+	assert.False(t, ok)
+	_, ok = z.canceler.remove(instancePath)
+	assert.False(t, ok)
+}
+
+func TestKeepalive(t *testing.T) {
+	conn := newFakeConn()
+	z := newTestRegistry(conn, WithRegisterMode(registries.RegisterModeAll))
+	info := newTestInfo(t, "127.0.0.1:20000", map[string]string{
+		registries.DubboServiceInterfaceKey:   testInterfaceName,
+		registries.DubboServiceApplicationKey: "app1",
+	})
+	assert.Nil(t, z.Register(info))
+	instancePath := fmt.Sprintf(registries.ServiceInstancesKeyTemplate, "app1") + "/127.0.0.1:20000"
+	instance, _ := conn.data(instancePath)
+	u := new(registries.URL)
+	assert.Nil(t, u.FromInfo(info))
+	interfacePath := u.GetRegistryServiceKey(registries.DefaultRegistryGroup) + "/" + u.ToString()
+
+	// the ephemeral nodes are recreated with the same content after the session is reestablished
+	conn.expireSession()
+	assert.Eventually(t, func() bool {
+		_, interfaceOK := conn.data(interfacePath)
+		data, instanceOK := conn.data(instancePath)
+		return interfaceOK && instanceOK && string(data) == string(instance)
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// the nodes deregistered are not recreated
+	assert.Nil(t, z.Deregister(info))
+	conn.expireSession()
+	time.Sleep(1500 * time.Millisecond)
+	_, ok := conn.data(interfacePath)
+	assert.False(t, ok)
+	_, ok = conn.data(instancePath)
+	assert.False(t, ok)
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/kitex/pkg/discovery"
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/go-zookeeper/zk"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+)
+
+// resolveInstance discovers the application instances exporting the interface. The applications are mapped by
+// /dubbo/mapping/<interface>, and the services exported by each instance are described by the MetadataInfo
+// of its revision, which is published to /dubbo/metadata/<app>/<revision>.
+func (z *zookeeperResolver) resolveInstance(interfaceName, svcGroup, svcVersion string) ([]discovery.Instance, error) {
+	mappingPath := fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, z.opt.RegistryGroup, interfaceName)
+	content, _, err := z.conn.Get(mappingPath)
+	if err != nil {
+		if errors.Is(err, zk.ErrNoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var instances []discovery.Instance
+	for _, app := range registries.ParseServiceNameMapping(string(content)) {
+		appPath := fmt.Sprintf(registries.ServiceInstancesKeyTemplate, app)
+		ids, _, err := z.conn.Children(appPath)
+		if err != nil {
+			if errors.Is(err, zk.ErrNoNode) {
+				z.retainMetadataInfo(app, nil)
+				continue
+			}
+			return nil, err
+		}
+		revisions := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			raw, _, err := z.conn.Get(appPath + "/" + id)
+			if err != nil {
+				// the instance may be deregistered after listed
+				if !errors.Is(err, zk.ErrNoNode) {
+					klog.Errorf("get dubbo instance %s/%s from zookeeper failed, err: %s", appPath, id, err)
+				}
+				continue
+			}
+			instance := new(registries.ServiceInstance)
+			if err := json.Unmarshal(raw, instance); err != nil {
+				klog.Errorf("invalid dubbo instance from zookeeper: %s, err: %s", raw, err)
+				continue
+			}
+			revisions[instance.Revision()] = true
+			metadata, err := z.getMetadataInfo(app, instance.Revision())
+			if err != nil {
+				klog.Errorf("get MetadataInfo of dubbo application %s revision %s failed, err: %s", app, instance.Revision(), err)
+				continue
+			}
+			if svc := metadata.GetService(interfaceName, svcGroup, svcVersion, registries.DefaultProtocol); svc != nil {
+				instances = append(instances, instance.ToInstance(svc))
+			}
+		}
+		z.retainMetadataInfo(app, revisions)
+	}
+	return instances, nil
+}
+
+// getMetadataInfo fetches the MetadataInfo of the application revision from the metadata report.
+// Providers should publish the MetadataInfo to zookeeper, e.g. dubbo.application.metadata-type=remote for dubbo-java.
+func (z *zookeeperResolver) getMetadataInfo(app, revision string) (*registries.MetadataInfo, error) {
+	z.mu.Lock()
+	metadata, ok := z.metadataCache[app][revision]
+	z.mu.Unlock()
+	if ok {
+		return metadata, nil
+	}
+	content, _, err := z.conn.Get(fmt.Sprintf(registries.MetadataInfoKeyTemplate, z.opt.RegistryGroup, app, revision))
+	if err != nil {
+		return nil, err
+	}
+	metadata = new(registries.MetadataInfo)
+	if err := json.Unmarshal(content, metadata); err != nil {
+		return nil, err
+	}
+	z.mu.Lock()
+	if z.metadataCache[app] == nil {
+		z.metadataCache[app] = make(map[string]*registries.MetadataInfo)
+	}
+	z.metadataCache[app][revision] = metadata
+	z.mu.Unlock()
+	return metadata, nil
+}
+
+// retainMetadataInfo evicts the cached MetadataInfo of app whose revisions are no longer used by its instances.
+func (z *zookeeperResolver) retainMetadataInfo(app string, revisions map[string]bool) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for revision := range z.metadataCache[app] {
+		if !revisions[revision] {
+			delete(z.metadataCache[app], revision)
+		}
+	}
+	if len(z.metadataCache[app]) == 0 {
+		delete(z.metadataCache, app)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2023 CloudWeGo Authors
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/kitex/pkg/registry"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/go-zookeeper/zk"
+	"github.com/kitex-contrib/codec-dubbo/registries"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInterfaceName = "org.cloudwego.kitex.samples.api.GreetProvider"
+
+// fakeConn serves the zookeeper nodes in memory, the children of a node are derived from the paths.
+type fakeConn struct {
+	nodes map[string][]byte
+}
+
+func (c *fakeConn) Get(path string) ([]byte, *zk.Stat, error) {
+	data, ok := c.nodes[path]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return data, &zk.Stat{}, nil
+}
+
+func (c *fakeConn) Children(path string) ([]string, *zk.Stat, error) {
+	var children []string
+	for p := range c.nodes {
+		if child := strings.TrimPrefix(p, path+"/"); child != p && !strings.Contains(child, "/") {
+			children = append(children, child)
+		}
+	}
+	if len(children) == 0 {
+		return nil, nil, zk.ErrNoNode
+	}
+	sort.Strings(children)
+	return children, &zk.Stat{}, nil
+}
+
+func newTestResolver(conn *fakeConn, opts ...Option) *zookeeperResolver {
+	opts = append([]Option{WithServers("127.0.0.1:2181")}, opts...)
+	return &zookeeperResolver{
+		conn:          conn,
+		opt:           newOptions(opts),
+		metadataCache: make(map[string]map[string]*registries.MetadataInfo),
+	}
+}
+
+// publishInstance adds the nodes of the instance registered by the registry of the application-level discovery.
+func publishInstance(t *testing.T, conn *fakeConn, addr string, metadata *registries.MetadataInfo) {
+	content, err := json.Marshal(metadata)
+	assert.Nil(t, err)
+	conn.nodes[fmt.Sprintf(registries.MetadataInfoKeyTemplate, registries.DefaultRegistryGroup, metadata.App, metadata.Revision)] = content
+	instance, err := registries.NewServiceInstance(addr, metadata)
+	assert.Nil(t, err)
+	content, err = json.Marshal(instance)
+	assert.Nil(t, err)
+	conn.nodes[fmt.Sprintf(registries.ServiceInstancesKeyTemplate, metadata.App)+"/"+addr] = content
+}
+
+func newTestMetadataInfo(app string, services ...*registries.ServiceInfo) *registries.MetadataInfo {
+	metadata := registries.NewMetadataInfo(app)
+	for _, s := range services {
+		metadata.AddService(s)
+	}
+	return metadata
+}
+
+func TestResolveInstance(t *testing.T) {
+	greetV1 := &registries.ServiceInfo{Name: testInterfaceName, Group: "g1", Version: "1.0.0", Protocol: "dubbo", Path: testInterfaceName}
+	greetV2 := &registries.ServiceInfo{Name: testInterfaceName, Group: "g1", Version: "2.0.0", Protocol: "dubbo", Path: testInterfaceName}
+	conn := &fakeConn{nodes: map[string][]byte{
+		fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, registries.DefaultRegistryGroup, testInterfaceName): []byte("app1, app2,app3"),
+	}}
+	app1 := newTestMetadataInfo("app1", greetV1)
+	publishInstance(t, conn, "127.0.0.1:20000", app1)
+	app2 := newTestMetadataInfo("app2", greetV2)
+	publishInstance(t, conn, "127.0.0.1:20001", app2)
+	z := newTestResolver(conn)
+
+	instances, err := z.resolveInstance(testInterfaceName, "g1", "1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Equal(t, "127.0.0.1:20000", instances[0].Address().String())
+	app, _ := instances[0].Tag(registries.DubboServiceApplicationKey)
+	assert.Equal(t, "app1", app)
+	assert.Equal(t, map[string]map[string]*registries.MetadataInfo{
+		"app1": {app1.Revision: app1},
+		"app2": {app2.Revision: app2},
+	}, z.metadataCache)
+
+	// the MetadataInfo of the old revision is evicted after app1 exports more services
+	app1 = newTestMetadataInfo("app1", greetV1, greetV2)
+	publishInstance(t, conn, "127.0.0.1:20000", app1)
+	instances, err = z.resolveInstance(testInterfaceName, "g1", "2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(instances))
+	assert.Equal(t, map[string]map[string]*registries.MetadataInfo{
+		"app1": {app1.Revision: app1},
+		"app2": {app2.Revision: app2},
+	}, z.metadataCache)
+
+	// the MetadataInfo of app2 is evicted after its instances are deregistered
+	delete(conn.nodes, fmt.Sprintf(registries.ServiceInstancesKeyTemplate, "app2")+"/127.0.0.1:20001")
+	instances, err = z.resolveInstance(testInterfaceName, "g1", "2.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Equal(t, map[string]map[string]*registries.MetadataInfo{
+		"app1": {app1.Revision: app1},
+	}, z.metadataCache)
+
+	// interfaces without service name mapping are not exported by applications
+	instances, err = z.resolveInstance("org.cloudwego.kitex.samples.api.EchoProvider", "", "")
+	assert.Nil(t, err)
+	assert.Empty(t, instances)
+}
+
+func TestResolve(t *testing.T) {
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:20002")
+	assert.Nil(t, err)
+	u := new(registries.URL)
+	assert.Nil(t, u.FromInfo(&registry.Info{
+		Addr: addr,
+		Tags: map[string]string{registries.DubboServiceInterfaceKey: testInterfaceName},
+	}))
+	conn := &fakeConn{nodes: map[string][]byte{
+		u.GetRegistryServiceKey(registries.DefaultRegistryGroup) + "/" + u.ToString(): nil,
+	}}
+	app := newTestMetadataInfo("app1",
+		&registries.ServiceInfo{Name: testInterfaceName, Protocol: "dubbo", Path: testInterfaceName})
+	target := rpcinfo.NewEndpointInfo("", "", nil, map[string]string{registries.DubboServiceInterfaceKey: testInterfaceName})
+
+	tests := []struct {
+		desc      string
+		mode      string
+		mapped    bool
+		addresses []string
+	}{
+		{
+			desc:      "interface",
+			mode:      registries.RegisterModeInterface,
+			mapped:    true,
+			addresses: []string{"127.0.0.1:20002"},
+		},
+		{
+			desc:      "instance",
+			mode:      registries.RegisterModeInstance,
+			mapped:    true,
+			addresses: []string{"127.0.0.1:20000"},
+		},
+		{
+			desc:      "instance without mapping",
+			mode:      registries.RegisterModeInstance,
+			addresses: nil,
+		},
+		{
+			desc:      "all",
+			mode:      registries.RegisterModeAll,
+			mapped:    true,
+			addresses: []string{"127.0.0.1:20000"},
+		},
+		{
+			desc:      "all falls back to interface",
+			mode:      registries.RegisterModeAll,
+			addresses: []string{"127.0.0.1:20002"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			mappingPath := fmt.Sprintf(registries.ServiceNameMappingKeyTemplate, registries.DefaultRegistryGroup, testInterfaceName)
+			delete(conn.nodes, mappingPath)
+			if test.mapped {
+				conn.nodes[mappingPath] = []byte("app1")
+				publishInstance(t, conn, "127.0.0.1:20000", app)
+			}
+			z := newTestResolver(conn, WithDiscoveryMode(test.mode))
+			desc := z.Target(context.Background(), target)
+			res, err := z.Resolve(context.Background(), desc)
+			assert.Nil(t, err)
+			assert.Equal(t, desc, res.CacheKey)
+			var addresses []string
+			for _, instance := range res.Instances {
+				addresses = append(addresses, instance.Address().String())
+			}
+			assert.Equal(t, test.addresses, addresses)
+		})
+	}
+}
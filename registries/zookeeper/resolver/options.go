@@ -20,6 +20,7 @@
 package resolver
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kitex-contrib/codec-dubbo/registries"
@@ -31,6 +32,7 @@ type Options struct {
 	SessionTimeout time.Duration
 	Username       string
 	Password       string
+	DiscoveryMode  string
 }
 
 func (o *Options) Apply(opts []Option) {
@@ -54,6 +56,13 @@ func newOptions(opts []Option) *Options {
 	if o.SessionTimeout == 0 {
 		o.SessionTimeout = defaultSessionTimeout
 	}
+	switch o.DiscoveryMode {
+	case "":
+		o.DiscoveryMode = registries.RegisterModeInterface
+	case registries.RegisterModeInterface, registries.RegisterModeInstance, registries.RegisterModeAll:
+	default:
+		panic(fmt.Sprintf("Unsupported DiscoveryMode %s, it should be one of interface, instance and all.", o.DiscoveryMode))
+	}
 	return o
 }
 
@@ -105,3 +114,16 @@ func WithPassword(password string) Option {
 		o.Password = password
 	}}
 }
+
+// WithDiscoveryMode configures how services are discovered, which is referred to the migration step of dubbo consumers.
+// registries.RegisterModeInterface discovers the interfaces registered under /dubbo/<interface>/providers,
+// which is the default mode.
+// registries.RegisterModeInstance maps the interface to applications by /dubbo/mapping/<interface> and discovers
+// the application instances exporting the interface according to their MetadataInfo.
+// registries.RegisterModeAll discovers application instances firstly and falls back to interfaces
+// if no instance exports the interface.
+func WithDiscoveryMode(mode string) Option {
+	return Option{F: func(o *Options) {
+		o.DiscoveryMode = mode
+	}}
+}
@@ -23,6 +23,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudwego/kitex/pkg/discovery"
@@ -37,10 +38,21 @@ const (
 	groupVersionSeparator = ":"
 )
 
+// zkConn is the subset of *zk.Conn used by the resolver.
+type zkConn interface {
+	Get(path string) ([]byte, *zk.Stat, error)
+	Children(path string) ([]string, *zk.Stat, error)
+}
+
 type zookeeperResolver struct {
-	conn       *zk.Conn
+	conn       zkConn
 	opt        *Options
 	uniqueName string
+
+	mu sync.Mutex
+	// key: app, val: MetadataInfo of the revisions used by the instances of app when it is resolved last time,
+	// the MetadataInfo of a revision never changes.
+	metadataCache map[string]map[string]*registries.MetadataInfo
 }
 
 func NewZookeeperResolver(opts ...Option) (discovery.Resolver, error) {
@@ -56,9 +68,10 @@ func NewZookeeperResolver(opts ...Option) (discovery.Resolver, error) {
 	}
 	uniName := "dubbo-zookeeper" + "/" + o.RegistryGroup
 	return &zookeeperResolver{
-		conn:       conn,
-		opt:        o,
-		uniqueName: uniName,
+		conn:          conn,
+		opt:           o,
+		uniqueName:    uniName,
+		metadataCache: make(map[string]map[string]*registries.MetadataInfo),
 	}, nil
 }
 
@@ -75,10 +88,38 @@ func (z *zookeeperResolver) Target(ctx context.Context, target rpcinfo.EndpointI
 
 func (z *zookeeperResolver) Resolve(ctx context.Context, desc string) (discovery.Result, error) {
 	regSvcKey, svcGroup, svcVersion := extractGroupVersion(desc)
-	rawURLs, _, err := z.conn.Children(regSvcKey)
+	var instances []discovery.Instance
+	var err error
+	switch z.opt.DiscoveryMode {
+	case registries.RegisterModeInterface:
+		instances, err = z.resolveInterface(regSvcKey, svcGroup, svcVersion)
+	case registries.RegisterModeInstance:
+		instances, err = z.resolveInstance(z.interfaceName(regSvcKey), svcGroup, svcVersion)
+	default:
+		instances, err = z.resolveInstance(z.interfaceName(regSvcKey), svcGroup, svcVersion)
+		if err != nil || len(instances) == 0 {
+			if err != nil {
+				klog.Warnf("resolve dubbo instances of %s failed, fall back to interfaces, err: %s", regSvcKey, err)
+			}
+			instances, err = z.resolveInterface(regSvcKey, svcGroup, svcVersion)
+		}
+	}
 	if err != nil {
 		return discovery.Result{}, err
 	}
+	return discovery.Result{
+		Cacheable: true,
+		CacheKey:  desc,
+		Instances: instances,
+	}, nil
+}
+
+// resolveInterface discovers the providers of the interface registered under regSvcKey.
+func (z *zookeeperResolver) resolveInterface(regSvcKey, svcGroup, svcVersion string) ([]discovery.Instance, error) {
+	rawURLs, _, err := z.conn.Children(regSvcKey)
+	if err != nil {
+		return nil, err
+	}
 	instances := make([]discovery.Instance, 0, len(rawURLs))
 	for _, rawURL := range rawURLs {
 		u := new(registries.URL)
@@ -95,11 +136,13 @@ func (z *zookeeperResolver) Resolve(ctx context.Context, desc string) (discovery
 		}
 		instances = append(instances, tmpInstance)
 	}
-	return discovery.Result{
-		Cacheable: true,
-		CacheKey:  desc,
-		Instances: instances,
-	}, nil
+	return instances, nil
+}
+
+// interfaceName extracts the interface name from regSvcKey /<RegistryGroup>/<interfaceName>/providers.
+func (z *zookeeperResolver) interfaceName(regSvcKey string) string {
+	name := strings.TrimPrefix(regSvcKey, "/"+z.opt.RegistryGroup+"/")
+	return strings.TrimSuffix(name, "/providers")
 }
 
 func (z *zookeeperResolver) Diff(cacheKey string, prev, next discovery.Result) (discovery.Change, bool) {
@@ -69,3 +69,9 @@ func TestExtractGroupVersion(t *testing.T) {
 		test.expected(t, remaining, group, version)
 	}
 }
+
+func TestInterfaceName(t *testing.T) {
+	z := &zookeeperResolver{opt: newOptions([]Option{WithServers("127.0.0.1:2181"), WithRegistryGroup("g1")})}
+	assert.Equal(t, "org.cloudwego.kitex.samples.api.GreetProvider",
+		z.interfaceName("/g1/org.cloudwego.kitex.samples.api.GreetProvider/providers"))
+}